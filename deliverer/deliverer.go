@@ -39,7 +39,8 @@ type DeliveryPersister interface {
 type DeliveryOptions struct {
 	// Initial amount of time to wait before retrying delivery.
 	InitialRetryTime time.Duration
-	// The longest amount of time to wait before retrying delivery.
+	// The longest amount of time to wait before retrying delivery, including
+	// waits requested by the receiving server with Retry-After.
 	MaximumRetryTime time.Duration
 	// Rate of backing off retries. Must be at least 1.
 	BackoffFactor float64
@@ -55,8 +56,48 @@ type DeliveryOptions struct {
 	//
 	// This field is optional.
 	Persister DeliveryPersister
+	// Rate limit of deliveries to each destination host, in addition to
+	// the global RateLimit, with bursts of up to PerHostBurst deliveries.
+	//
+	// These fields are optional. When zero, deliveries to a host are not
+	// limited separately.
+	PerHostRateLimit rate.Limit
+	PerHostBurst     int
+	// Maximum number of deliveries in flight to each destination host.
+	//
+	// This field is optional. When zero, there is no limit.
+	MaxConcurrentPerHost int
+	// Number of consecutive failed deliveries to a host after which no
+	// more are sent to it for the CircuitBreakerCooldown. Once the cooldown
+	// has passed, a single delivery probes the host: if it succeeds, all
+	// deliveries resume, otherwise the cooldown starts again. Deliveries
+	// held back in the meantime are not counted as retries.
+	//
+	// This field is optional. When zero, there is no circuit breaker.
+	CircuitBreakerThreshold int
+	CircuitBreakerCooldown  time.Duration
+	// OnCircuitChange is called whenever deliveries to a host are stopped
+	// (open is true) or resumed (open is false) by the circuit breaker.
+	//
+	// This field is optional.
+	OnCircuitChange func(host string, open bool)
+	// OnGone is called when a peer responds that the IRI a delivery was
+	// sent to is gone, so the application may stop delivering to the
+	// actor it belonged to. Such deliveries are not retried.
+	//
+	// This field is optional.
+	OnGone func(to *url.URL)
+	// Observer is notified of every delivery attempt, retry, and change of
+	// a circuit breaker, such as to gather metrics.
+	//
+	// This field is optional.
+	Observer Observer
 }
 
+// errorBufferSize is the number of errors the Errors channel holds before
+// further errors are dropped.
+const errorBufferSize = 64
+
 var _ pub.Deliverer = &DelivererPool{}
 
 type DelivererPool struct {
@@ -73,6 +114,12 @@ type DelivererPool struct {
 	maxNumberRetries int
 	// Enforces speed limit of retries
 	limiter *rate.Limiter
+	// Enforces per host limits and circuit breaking
+	hosts *hosts
+	// Notifies of gone recipients
+	onGone func(to *url.URL)
+	// Notified of every step of every delivery
+	observer Observer
 	// Allow graceful cancelling
 	ctx      context.Context
 	cancel   context.CancelFunc
@@ -85,6 +132,16 @@ type DelivererPool struct {
 
 func NewDelivererPool(d DeliveryOptions) *DelivererPool {
 	ctx, cancel := context.WithCancel(context.Background())
+	var observer Observer = noopObserver{}
+	if d.Observer != nil {
+		observer = d.Observer
+	}
+	onChange := func(host string, open bool) {
+		if d.OnCircuitChange != nil {
+			d.OnCircuitChange(host, open)
+		}
+		observer.CircuitChanged(host, open)
+	}
 	return &DelivererPool{
 		persister:        d.Persister,
 		initialRetryTime: d.InitialRetryTime,
@@ -92,12 +149,15 @@ func NewDelivererPool(d DeliveryOptions) *DelivererPool {
 		retryTimeFactor:  d.BackoffFactor,
 		maxNumberRetries: d.MaxRetries,
 		limiter:          d.RateLimit,
+		hosts:            newHosts(d, onChange),
+		onGone:           d.OnGone,
+		observer:         observer,
 		ctx:              ctx,
 		cancel:           cancel,
 		timerId:          0,
 		timerMap:         make(map[uint64]*time.Timer, 0),
 		mu:               sync.Mutex{},
-		errChan:          make(chan error, 0),
+		errChan:          make(chan error, errorBufferSize),
 	}
 }
 
@@ -106,6 +166,7 @@ type retryData struct {
 	n        int
 	f        func() error
 	id       string
+	to       *url.URL
 }
 
 func (r retryData) NextRetry(factor float64, max time.Duration) retryData {
@@ -118,6 +179,7 @@ func (r retryData) NextRetry(factor float64, max time.Duration) retryData {
 		n:        r.n + 1,
 		f:        r.f,
 		id:       r.id,
+		to:       r.to,
 	}
 }
 
@@ -142,6 +204,7 @@ func (d *DelivererPool) Do(b []byte, to *url.URL, sendFn func([]byte, *url.URL)
 			n:        0,
 			f:        f,
 			id:       id,
+			to:       to,
 		})
 	}()
 }
@@ -160,10 +223,36 @@ func (d *DelivererPool) Restart(b []byte, to *url.URL, id string, sendFn func([]
 			n:        0,
 			f:        f,
 			id:       id,
+			to:       to,
 		})
 	}()
 }
 
+// resume continues a delivery persisted by a previous DelivererPool, counting
+// the attempts it already made towards the maximum number of retries. It is
+// next attempted at the given time, or immediately if the time has passed.
+func (d *DelivererPool) resume(b []byte, to *url.URL, id string, attempts int, next time.Time, sendFn func([]byte, *url.URL) error) {
+	r := retryData{
+		nextWait: d.initialRetryTime,
+		n:        0,
+		f: func() error {
+			return sendFn(b, to)
+		},
+		id: id,
+		to: to,
+	}
+	for r.n < attempts {
+		r = r.NextRetry(d.retryTimeFactor, d.maxRetryTime)
+	}
+	if wait := time.Until(next); wait > 0 {
+		d.addTimer(wait, func() {
+			d.do(r)
+		})
+	} else {
+		go d.do(r)
+	}
+}
+
 // Stop turns down and stops any in-flight requests or retries.
 func (d *DelivererPool) Stop() {
 	d.cancel()
@@ -172,27 +261,77 @@ func (d *DelivererPool) Stop() {
 
 // Provides a channel streaming any errors the pool encounters, including errors
 // that it retries on.
+//
+// The channel is buffered, and errors are dropped while it is full so that
+// deliveries never wait on it. Use an Observer to be notified of every failed
+// delivery.
 func (d *DelivererPool) Errors() <-chan error {
 	return d.errChan
 }
 
 func (d *DelivererPool) do(r retryData) {
 	if err := d.limiter.Wait(d.ctx); err != nil {
-		if d.persister != nil {
-			d.persister.Cancel(r.id)
-		}
-		d.errChan <- err
+		d.cancelled(r, err)
+		return
+	}
+	h := d.hosts.get(r.to.Host)
+	ok, probe, wait := d.hosts.allow(h, time.Now())
+	if !ok {
+		d.addTimer(wait, func() {
+			d.do(r)
+		})
+		return
+	}
+	release, err := d.hosts.acquire(d.ctx, h, probe)
+	if err != nil {
+		d.cancelled(r, err)
 		return
 	}
-	if err := r.f(); err != nil {
-		d.errChan <- err
-		if r.ShouldRetry(d.maxNumberRetries) {
+	d.observer.AttemptStarted(r.to, r.n+1)
+	start := time.Now()
+	err = r.f()
+	latency := time.Since(start)
+	release()
+	se, isStatus := err.(*pub.HttpStatusError)
+	statusCode := 0
+	if isStatus {
+		statusCode = se.StatusCode
+	}
+	d.observer.AttemptFinished(r.to, r.n+1, latency, statusCode, err)
+	if isStatus && se.Permanent() {
+		// The host is responding, even though it will never accept this
+		// delivery.
+		d.hosts.report(r.to.Host, h, time.Now(), nil)
+	} else {
+		d.hosts.report(r.to.Host, h, time.Now(), err)
+	}
+	if err != nil {
+		d.reportError(err)
+		if isStatus && se.Gone() && d.onGone != nil {
+			d.onGone(r.to)
+		}
+		if isStatus && se.Permanent() {
+			d.observer.GaveUp(r.to, r.n+1, err)
 			if d.persister != nil {
+				d.persister.Undeliverable(r.id)
+			}
+		} else if r.ShouldRetry(d.maxNumberRetries) {
+			if isStatus && se.RetryAfter > r.nextWait {
+				r.nextWait = se.RetryAfter
+				if r.nextWait > d.maxRetryTime {
+					r.nextWait = d.maxRetryTime
+				}
+			}
+			if rr, ok := d.persister.(RetryRecorder); ok {
+				rr.RetryingAt(r.id, r.n+1, time.Now().Add(r.nextWait))
+			} else if d.persister != nil {
 				d.persister.Retrying(r.id)
 			}
+			d.observer.RetryScheduled(r.to, r.n+1, r.nextWait)
 			d.addClosableTimer(r)
 		} else {
-			d.errChan <- fmt.Errorf("delivery tried maximum number of times")
+			d.observer.GaveUp(r.to, r.n+1, err)
+			d.reportError(fmt.Errorf("delivery tried maximum number of times"))
 			if d.persister != nil {
 				d.persister.Undeliverable(r.id)
 			}
@@ -204,13 +343,34 @@ func (d *DelivererPool) do(r retryData) {
 	}
 }
 
+func (d *DelivererPool) cancelled(r retryData, err error) {
+	if d.persister != nil {
+		d.persister.Cancel(r.id)
+	}
+	d.reportError(err)
+}
+
+// reportError sends the error on the Errors channel, unless it is full.
+func (d *DelivererPool) reportError(err error) {
+	select {
+	case d.errChan <- err:
+	default:
+	}
+}
+
 func (d *DelivererPool) addClosableTimer(r retryData) {
+	d.addTimer(r.nextWait, func() {
+		d.do(r.NextRetry(d.retryTimeFactor, d.maxRetryTime))
+	})
+}
+
+func (d *DelivererPool) addTimer(wait time.Duration, f func()) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	id := d.timerId
 	d.timerId++
-	d.timerMap[id] = time.AfterFunc(r.nextWait, func() {
-		d.do(r.NextRetry(d.retryTimeFactor, d.maxRetryTime))
+	d.timerMap[id] = time.AfterFunc(wait, func() {
+		f()
 		d.removeTimer(id)
 	})
 }
@@ -2,8 +2,11 @@ package deliverer
 
 import (
 	"fmt"
+	"github.com/go-fed/activity/pub"
 	"github.com/go-test/deep"
 	"golang.org/x/time/rate"
+	"math"
+	"net/http"
 	"net/url"
 	"sync"
 	"testing"
@@ -116,6 +119,16 @@ func (m *mockDeliveryPersister) Undeliverable(id string) {
 	}
 }
 
+// state returns the state of the delivery with the id.
+func (m *mockDeliveryPersister) state(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id == id1 {
+		return m.id1State
+	}
+	return m.id2State
+}
+
 func TestDelivererPoolSuccessNoPersister(t *testing.T) {
 	testSendFn := func(b []byte, u *url.URL) error {
 		if diff := deep.Equal(b, testBytes); diff != nil {
@@ -156,8 +169,8 @@ func TestDelivererPoolSuccessPersister(t *testing.T) {
 	})
 	pool.Do(testBytes, testURL, testSendFn)
 	time.Sleep(time.Microsecond * 500)
-	if p.id1State != successful {
-		t.Fatalf("want: %s, got %s", successful, p.id1State)
+	if state := p.state(id1); state != successful {
+		t.Fatalf("want: %s, got %s", successful, state)
 	}
 }
 
@@ -181,8 +194,8 @@ func TestRestartSuccess(t *testing.T) {
 	})
 	pool.Restart(testBytes, testURL, id2, testSendFn)
 	time.Sleep(time.Microsecond * 500)
-	if p.id2State != successful {
-		t.Fatalf("want: %s, got %s", successful, p.id1State)
+	if state := p.state(id2); state != successful {
+		t.Fatalf("want: %s, got %s", successful, state)
 	}
 }
 
@@ -197,13 +210,14 @@ func TestDelivererPoolRetrying(t *testing.T) {
 	}
 	p := newMockDeliveryPersister(t)
 	pool := NewDelivererPool(DeliveryOptions{
-		InitialRetryTime: time.Microsecond,
-		MaximumRetryTime: time.Microsecond,
+		InitialRetryTime: time.Minute,
+		MaximumRetryTime: time.Minute,
 		BackoffFactor:    2,
 		MaxRetries:       1,
 		RateLimit:        rate.NewLimiter(1000000, 10000000),
 		Persister:        p,
 	})
+	defer pool.Stop()
 	pool.Do(testBytes, testURL, testSendFn)
 	time.Sleep(time.Microsecond * 500)
 	select {
@@ -212,8 +226,8 @@ func TestDelivererPoolRetrying(t *testing.T) {
 		t.Fatal("expected error")
 	}
 	time.Sleep(time.Microsecond * 500)
-	if p.id1State != retrying {
-		t.Fatalf("want: %s, got %s", retrying, p.id1State)
+	if state := p.state(id1); state != retrying {
+		t.Fatalf("want: %s, got %s", retrying, state)
 	}
 }
 
@@ -243,8 +257,8 @@ func TestDelivererPoolUndeliverable(t *testing.T) {
 	time.Sleep(time.Microsecond * 500)
 	<-pool.Errors()
 	time.Sleep(time.Microsecond * 500)
-	if p.id1State != undeliverable {
-		t.Fatalf("want: %s, got %s", undeliverable, p.id1State)
+	if state := p.state(id1); state != undeliverable {
+		t.Fatalf("want: %s, got %s", undeliverable, state)
 	}
 }
 
@@ -259,13 +273,14 @@ func TestRestartRetrying(t *testing.T) {
 	}
 	p := newMockDeliveryPersister(t)
 	pool := NewDelivererPool(DeliveryOptions{
-		InitialRetryTime: time.Microsecond,
-		MaximumRetryTime: time.Microsecond,
+		InitialRetryTime: time.Minute,
+		MaximumRetryTime: time.Minute,
 		BackoffFactor:    2,
 		MaxRetries:       1,
 		RateLimit:        rate.NewLimiter(1000000, 10000000),
 		Persister:        p,
 	})
+	defer pool.Stop()
 	pool.Restart(testBytes, testURL, id2, testSendFn)
 	time.Sleep(time.Microsecond * 500)
 	select {
@@ -274,8 +289,8 @@ func TestRestartRetrying(t *testing.T) {
 		t.Fatal("expected error")
 	}
 	time.Sleep(time.Microsecond * 500)
-	if p.id2State != retrying {
-		t.Fatalf("want: %s, got %s", retrying, p.id2State)
+	if state := p.state(id2); state != retrying {
+		t.Fatalf("want: %s, got %s", retrying, state)
 	}
 }
 
@@ -305,7 +320,170 @@ func TestRestartUndeliverable(t *testing.T) {
 	time.Sleep(time.Microsecond * 500)
 	<-pool.Errors()
 	time.Sleep(time.Microsecond * 500)
-	if p.id2State != undeliverable {
-		t.Fatalf("want: %s, got %s", undeliverable, p.id2State)
+	if state := p.state(id2); state != undeliverable {
+		t.Fatalf("want: %s, got %s", undeliverable, state)
+	}
+}
+
+func TestDelivererPoolGone(t *testing.T) {
+	calls := 0
+	testSendFn := func(b []byte, u *url.URL) error {
+		calls++
+		return &pub.HttpStatusError{Method: "POST", IRI: u, StatusCode: http.StatusGone, Status: "Gone"}
+	}
+	gone := make(chan *url.URL, 1)
+	p := newMockDeliveryPersister(t)
+	pool := NewDelivererPool(DeliveryOptions{
+		InitialRetryTime: time.Microsecond,
+		MaximumRetryTime: time.Microsecond,
+		BackoffFactor:    2,
+		MaxRetries:       5,
+		RateLimit:        rate.NewLimiter(1000000, 10000000),
+		Persister:        p,
+		OnGone: func(to *url.URL) {
+			gone <- to
+		},
+	})
+	pool.Do(testBytes, testURL, testSendFn)
+	<-pool.Errors()
+	if u := <-gone; u != testURL {
+		t.Fatalf("want: %s, got %s", testURL, u)
+	}
+	time.Sleep(time.Millisecond)
+	if state := p.state(id1); state != undeliverable {
+		t.Fatalf("want: %s, got %s", undeliverable, state)
+	} else if calls != 1 {
+		t.Fatalf("want: 1 attempt, got %d", calls)
+	}
+}
+
+func TestDelivererPoolRetryAfter(t *testing.T) {
+	const wait = 20 * time.Millisecond
+	var mu sync.Mutex
+	var attempts []time.Time
+	testSendFn := func(b []byte, u *url.URL) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts = append(attempts, time.Now())
+		if len(attempts) == 1 {
+			return &pub.HttpStatusError{Method: "POST", IRI: u, StatusCode: http.StatusTooManyRequests, Status: "Too Many Requests", RetryAfter: wait}
+		}
+		return nil
+	}
+	pool := NewDelivererPool(DeliveryOptions{
+		InitialRetryTime: time.Microsecond,
+		MaximumRetryTime: time.Minute,
+		BackoffFactor:    2,
+		MaxRetries:       5,
+		RateLimit:        rate.NewLimiter(1000000, 10000000),
+	})
+	defer pool.Stop()
+	pool.Do(testBytes, testURL, testSendFn)
+	<-pool.Errors()
+	time.Sleep(2 * wait)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 2 {
+		t.Fatalf("want: 2 attempts, got %d", len(attempts))
+	} else if d := attempts[1].Sub(attempts[0]); d < wait {
+		t.Fatalf("want: retry after at least %s, got %s", wait, d)
+	}
+}
+
+func TestDelivererPoolRetryAfterBounded(t *testing.T) {
+	const maxWait = 10 * time.Millisecond
+	var mu sync.Mutex
+	attempts := 0
+	testSendFn := func(b []byte, u *url.URL) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			return &pub.HttpStatusError{Method: "POST", IRI: u, StatusCode: http.StatusServiceUnavailable, Status: "Service Unavailable", RetryAfter: time.Duration(math.MaxInt64)}
+		}
+		return nil
+	}
+	pool := NewDelivererPool(DeliveryOptions{
+		InitialRetryTime: time.Microsecond,
+		MaximumRetryTime: maxWait,
+		BackoffFactor:    2,
+		MaxRetries:       5,
+		RateLimit:        rate.NewLimiter(1000000, 10000000),
+	})
+	defer pool.Stop()
+	pool.Do(testBytes, testURL, testSendFn)
+	<-pool.Errors()
+	time.Sleep(5 * maxWait)
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Fatalf("want: 2 attempts, got %d", attempts)
+	}
+}
+
+// recordingObserver records the names of the events it is notified of.
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recordingObserver) record(format string, a ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf(format, a...))
+}
+
+func (r *recordingObserver) AttemptStarted(to *url.URL, attempt int) {
+	r.record("started %d", attempt)
+}
+
+func (r *recordingObserver) AttemptFinished(to *url.URL, attempt int, latency time.Duration, statusCode int, err error) {
+	r.record("finished %d %d", attempt, statusCode)
+}
+
+func (r *recordingObserver) RetryScheduled(to *url.URL, attempt int, wait time.Duration) {
+	r.record("retry %d", attempt)
+}
+
+func (r *recordingObserver) GaveUp(to *url.URL, attempts int, err error) {
+	r.record("gave up %d", attempts)
+}
+
+func (r *recordingObserver) CircuitChanged(host string, open bool) {
+	r.record("circuit %v", open)
+}
+
+func TestDelivererPoolObserver(t *testing.T) {
+	testSendFn := func(b []byte, u *url.URL) error {
+		return &pub.HttpStatusError{Method: "POST", IRI: u, StatusCode: http.StatusServiceUnavailable, Status: "Service Unavailable"}
+	}
+	o := &recordingObserver{}
+	pool := NewDelivererPool(DeliveryOptions{
+		InitialRetryTime:        time.Microsecond,
+		MaximumRetryTime:        time.Microsecond,
+		BackoffFactor:           2,
+		MaxRetries:              1,
+		RateLimit:               rate.NewLimiter(1000000, 10000000),
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerCooldown:  time.Minute,
+		Observer:                o,
+	})
+	defer pool.Stop()
+	// Nobody reads the errors, which must not hold up the deliveries.
+	pool.Do(testBytes, testURL, testSendFn)
+	time.Sleep(10 * time.Millisecond)
+	want := []string{
+		"started 1",
+		"finished 1 503",
+		"retry 1",
+		"started 2",
+		"finished 2 503",
+		"circuit true",
+		"gave up 2",
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if diff := deep.Equal(o.events, want); diff != nil {
+		t.Fatal(diff)
 	}
 }
@@ -0,0 +1,377 @@
+package deliverer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// compactThreshold is the number of records appended to a FilePersister's log
+// after which it is compacted automatically.
+const compactThreshold = 1000
+
+// The operations recorded in a FilePersister's log.
+const (
+	opSending       = "sending"
+	opCancel        = "cancel"
+	opRetrying      = "retrying"
+	opSuccessful    = "successful"
+	opUndeliverable = "undeliverable"
+)
+
+// RetryRecorder is a DeliveryPersister that also records how many times a
+// delivery has been attempted and when it will next be attempted.
+//
+// When the DeliveryOptions' Persister implements it, a DelivererPool calls
+// RetryingAt in place of Retrying.
+type RetryRecorder interface {
+	DeliveryPersister
+	// RetryingAt indicates the specified delivery failed its attempts so
+	// far and will be attempted again at the given time.
+	RetryingAt(id string, attempts int, next time.Time)
+}
+
+var _ RetryRecorder = &FilePersister{}
+
+// fileRecord is a single line of a FilePersister's log.
+type fileRecord struct {
+	Op       string     `json:"op"`
+	Id       string     `json:"id"`
+	To       string     `json:"to,omitempty"`
+	Body     []byte     `json:"body,omitempty"`
+	Attempts int        `json:"attempts,omitempty"`
+	Next     *time.Time `json:"next,omitempty"`
+}
+
+// pendingDelivery is a delivery that has neither succeeded nor been given up
+// on.
+type pendingDelivery struct {
+	to       *url.URL
+	body     []byte
+	attempts int
+	next     time.Time
+}
+
+// FilePersister is a DeliveryPersister that appends every change in delivery
+// state to a local file, so that deliveries still being sent or retried when
+// the application stops are resumed once it starts again with Replay.
+//
+// Each change is synced to disk before the DelivererPool continues. Finished
+// deliveries are dropped from the file when it is opened, whenever Compact is
+// called, and automatically once more than compactThreshold records, and more
+// than twice as many records as there are pending deliveries, have been
+// appended since the file was last compacted.
+type FilePersister struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	nextId  uint64
+	pending map[string]*pendingDelivery
+	// appended is the number of records written since the log was last
+	// compacted.
+	appended int
+	// err is the first error encountered writing to the file, since the
+	// DeliveryPersister methods cannot return one.
+	err error
+}
+
+// NewFilePersister opens the log at path, creating it if it does not exist,
+// and compacts it.
+//
+// Only one FilePersister may have a file open at a time.
+func NewFilePersister(path string) (*FilePersister, error) {
+	p := &FilePersister{
+		path:    path,
+		pending: make(map[string]*pendingDelivery),
+	}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	if err := p.Compact(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// load reads the existing log into the pending deliveries.
+//
+// A final line cut short by a crash is ignored.
+func (p *FilePersister) load() error {
+	f, err := os.Open(p.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// Either nothing remains, or the last record was never
+			// completely written.
+			return nil
+		} else if err != nil {
+			return err
+		}
+		var rec fileRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("malformed delivery record in %s: %s", p.path, err)
+		}
+		if err := p.apply(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// apply updates the pending deliveries with a record.
+func (p *FilePersister) apply(rec fileRecord) error {
+	if n, err := strconv.ParseUint(rec.Id, 10, 64); err == nil && n >= p.nextId {
+		p.nextId = n + 1
+	}
+	switch rec.Op {
+	case opSending:
+		to, err := url.Parse(rec.To)
+		if err != nil {
+			return err
+		}
+		pd := &pendingDelivery{
+			to:       to,
+			body:     rec.Body,
+			attempts: rec.Attempts,
+		}
+		if rec.Next != nil {
+			pd.next = *rec.Next
+		}
+		p.pending[rec.Id] = pd
+	case opRetrying:
+		if pd, ok := p.pending[rec.Id]; ok {
+			pd.attempts = rec.Attempts
+			if rec.Next != nil {
+				pd.next = *rec.Next
+			}
+		}
+	case opSuccessful, opUndeliverable:
+		delete(p.pending, rec.Id)
+	case opCancel:
+		// Cancelled deliveries remain pending.
+	default:
+		return fmt.Errorf("unknown delivery record operation %q in %s", rec.Op, p.path)
+	}
+	return nil
+}
+
+// append applies a record, then writes and syncs it to the log.
+//
+// The lock must be held by the caller.
+func (p *FilePersister) append(rec fileRecord) {
+	if err := p.apply(rec); err != nil {
+		p.setErr(err)
+		return
+	}
+	if p.f == nil {
+		p.setErr(fmt.Errorf("file persister %s is closed", p.path))
+		return
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	if _, err := p.f.Write(append(b, '\n')); err != nil {
+		p.setErr(err)
+		return
+	}
+	if err := p.f.Sync(); err != nil {
+		p.setErr(err)
+		return
+	}
+	p.appended++
+	if p.appended > compactThreshold && p.appended > 2*len(p.pending) {
+		// The old log remains in use if it cannot be replaced, so
+		// compacting is attempted again after as many more records.
+		if err := p.compact(); err != nil {
+			p.appended = 0
+		}
+	}
+}
+
+// setErr keeps the first error encountered.
+//
+// The lock must be held by the caller.
+func (p *FilePersister) setErr(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// Sending records a new delivery, returning its id.
+func (p *FilePersister) Sending(b []byte, to *url.URL) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := strconv.FormatUint(p.nextId, 10)
+	p.append(fileRecord{
+		Op:   opSending,
+		Id:   id,
+		To:   to.String(),
+		Body: b,
+	})
+	return id
+}
+
+// Cancel records that the delivery was interrupted. It remains pending.
+func (p *FilePersister) Cancel(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.append(fileRecord{Op: opCancel, Id: id})
+}
+
+// Successful records that the delivery is finished.
+func (p *FilePersister) Successful(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.append(fileRecord{Op: opSuccessful, Id: id})
+}
+
+// Retrying records another attempt of the delivery, without a time for the
+// next attempt.
+func (p *FilePersister) Retrying(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	attempts := 1
+	if pd, ok := p.pending[id]; ok {
+		attempts = pd.attempts + 1
+	}
+	p.append(fileRecord{Op: opRetrying, Id: id, Attempts: attempts})
+}
+
+// RetryingAt records the number of attempts of the delivery so far and when
+// it will next be attempted.
+func (p *FilePersister) RetryingAt(id string, attempts int, next time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.append(fileRecord{Op: opRetrying, Id: id, Attempts: attempts, Next: &next})
+}
+
+// Undeliverable records that the delivery was given up on.
+func (p *FilePersister) Undeliverable(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.append(fileRecord{Op: opUndeliverable, Id: id})
+}
+
+// Replay resumes every pending delivery in the DelivererPool, preserving the
+// number of attempts already made and waiting until the recorded time of the
+// next attempt if it has not yet passed.
+//
+// The log does not record which actor sent each payload, so sendFn must
+// determine who to deliver on behalf of, such as from the payload's 'actor'.
+func (p *FilePersister) Replay(d *DelivererPool, sendFn func([]byte, *url.URL) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, pd := range p.pending {
+		d.resume(pd.body, pd.to, id, pd.attempts, pd.next, sendFn)
+	}
+}
+
+// Compact rewrites the log to hold only the pending deliveries, replacing the
+// old log atomically. If the old log cannot be replaced, it continues to be
+// appended to.
+func (p *FilePersister) Compact() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.compact()
+}
+
+// compact rewrites the log as described by Compact.
+//
+// The lock must be held by the caller.
+func (p *FilePersister) compact() error {
+	var buf bytes.Buffer
+	for id, pd := range p.pending {
+		rec := fileRecord{
+			Op:       opSending,
+			Id:       id,
+			To:       pd.to.String(),
+			Body:     pd.body,
+			Attempts: pd.attempts,
+		}
+		if !pd.next.IsZero() {
+			next := pd.next
+			rec.Next = &next
+		}
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	// The new log is kept open to be appended to once it replaces the old
+	// one, so the old log remains in use if it cannot be replaced.
+	tmp, err := os.OpenFile(p.path+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p.path); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if dir, err := os.Open(filepath.Dir(p.path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	if p.f != nil {
+		p.f.Close()
+	}
+	p.f = tmp
+	p.appended = 0
+	return nil
+}
+
+// Pending returns the number of deliveries that have neither succeeded nor
+// been given up on.
+func (p *FilePersister) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pending)
+}
+
+// Err returns the first error encountered writing to the log. Once one has
+// occurred, changes in delivery state may not survive a restart.
+func (p *FilePersister) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+// Close closes the log.
+func (p *FilePersister) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.f == nil {
+		return nil
+	}
+	err := p.f.Close()
+	p.f = nil
+	return err
+}
@@ -0,0 +1,154 @@
+package deliverer
+
+import (
+	"golang.org/x/time/rate"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestFilePersister(t *testing.T) (string, *FilePersister) {
+	dir, err := ioutil.TempDir("", "deliverer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "deliveries.log")
+	p, err := NewFilePersister(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, p
+}
+
+func TestFilePersisterRecoversPending(t *testing.T) {
+	path, p := newTestFilePersister(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	next := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	done := p.Sending(testBytes, testURL)
+	retried := p.Sending(testBytes, testURL)
+	cancelled := p.Sending(testBytes, testURL)
+	p.Successful(done)
+	p.RetryingAt(retried, 2, next)
+	p.Cancel(cancelled)
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	} else if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a crash part of the way through writing a record.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"op":"successful","id":"`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	p, err = NewFilePersister(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if n := p.Pending(); n != 2 {
+		t.Fatalf("want: 2 pending, got %d", n)
+	}
+	pd := p.pending[retried]
+	if pd == nil {
+		t.Fatal("retried delivery is not pending")
+	} else if pd.attempts != 2 {
+		t.Fatalf("want: 2 attempts, got %d", pd.attempts)
+	} else if !pd.next.Equal(next) {
+		t.Fatalf("want: next attempt %s, got %s", next, pd.next)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(b), "\n"); lines != 2 {
+		t.Fatalf("want: 2 records after compaction, got %d", lines)
+	}
+	if id := p.Sending(testBytes, testURL); id == done || id == retried || id == cancelled {
+		t.Fatalf("reused delivery id %s", id)
+	}
+}
+
+func TestFilePersisterCompactRenameFails(t *testing.T) {
+	path, p := newTestFilePersister(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer p.Close()
+	p.Sending(testBytes, testURL)
+	// Replacing the log with a directory makes renaming onto it fail.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	} else if err := os.Mkdir(path, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Compact(); err == nil {
+		t.Fatal("want: compaction error, got none")
+	}
+	p.Sending(testBytes, testURL)
+	if err := p.Err(); err != nil {
+		t.Fatalf("want: old log still in use, got %v", err)
+	} else if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("want: temporary log removed, got %v", err)
+	}
+}
+
+func TestFilePersisterReplay(t *testing.T) {
+	path, p := newTestFilePersister(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	p.Sending(testBytes, testURL)
+	p.Retrying(p.Sending(testBytes, testURL))
+	var mu sync.Mutex
+	var sent []string
+	testSendFn := func(b []byte, u *url.URL) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, u.String())
+		return nil
+	}
+	pool := NewDelivererPool(DeliveryOptions{
+		InitialRetryTime: time.Microsecond,
+		MaximumRetryTime: time.Microsecond,
+		BackoffFactor:    2,
+		MaxRetries:       3,
+		RateLimit:        rate.NewLimiter(1000000, 10000000),
+		Persister:        p,
+	})
+	p.Replay(pool, testSendFn)
+	time.Sleep(time.Millisecond * 10)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 2 {
+		t.Fatalf("want: 2 deliveries, got %d", len(sent))
+	} else if n := p.Pending(); n != 0 {
+		t.Fatalf("want: 0 pending, got %d", n)
+	}
+}
+
+func TestFilePersisterCompactsAutomatically(t *testing.T) {
+	path, p := newTestFilePersister(t)
+	defer os.RemoveAll(filepath.Dir(path))
+	defer p.Close()
+	pending := p.Sending(testBytes, testURL)
+	for i := 0; i <= compactThreshold/2; i++ {
+		p.Successful(p.Sending(testBytes, testURL))
+	}
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(b), "\n"); lines >= compactThreshold {
+		t.Fatalf("want: fewer than %d records after compaction, got %d", compactThreshold, lines)
+	} else if p.pending[pending] == nil {
+		t.Fatal("pending delivery was dropped")
+	}
+}
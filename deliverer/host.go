@@ -0,0 +1,148 @@
+package deliverer
+
+import (
+	"context"
+	"golang.org/x/time/rate"
+	"sync"
+	"time"
+)
+
+// hostState tracks the deliveries to a single destination host.
+type hostState struct {
+	// Enforces the speed limit of deliveries to this host. Optional.
+	limiter *rate.Limiter
+	// Limits the number of deliveries in flight to this host. Optional.
+	sem chan struct{}
+
+	mu sync.Mutex // Limits concurrent access to the circuit breaker state
+	// Number of consecutive failed deliveries.
+	failures int
+	// Whether deliveries are stopped, and until when.
+	open      bool
+	openUntil time.Time
+	// Whether a single delivery is probing the host while the circuit is
+	// open.
+	probing bool
+}
+
+// hosts lazily creates the state of each destination host, and applies the
+// circuit breaker to them.
+type hosts struct {
+	rateLimit     rate.Limit
+	burst         int
+	maxConcurrent int
+	threshold     int
+	cooldown      time.Duration
+	onChange      func(host string, open bool)
+
+	mu     sync.Mutex // Limits concurrent access to states
+	states map[string]*hostState
+}
+
+func newHosts(d DeliveryOptions, onChange func(host string, open bool)) *hosts {
+	return &hosts{
+		rateLimit:     d.PerHostRateLimit,
+		burst:         d.PerHostBurst,
+		maxConcurrent: d.MaxConcurrentPerHost,
+		threshold:     d.CircuitBreakerThreshold,
+		cooldown:      d.CircuitBreakerCooldown,
+		onChange:      onChange,
+		states:        make(map[string]*hostState),
+	}
+}
+
+func (h *hosts) get(host string) *hostState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.states[host]
+	if !ok {
+		s = &hostState{}
+		if h.rateLimit > 0 {
+			burst := h.burst
+			if burst < 1 {
+				burst = 1
+			}
+			s.limiter = rate.NewLimiter(h.rateLimit, burst)
+		}
+		if h.maxConcurrent > 0 {
+			s.sem = make(chan struct{}, h.maxConcurrent)
+		}
+		h.states[host] = s
+	}
+	return s
+}
+
+// allow determines whether a delivery may be sent to the host now. If not, it
+// returns how long to wait before asking again.
+//
+// Once the circuit has been open for the cooldown, a single delivery is
+// allowed through to probe whether the host has recovered. The probe is
+// reported as such, and must be passed to acquire.
+func (h *hosts) allow(s *hostState, now time.Time) (ok, probe bool, wait time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.open {
+		return true, false, 0
+	} else if now.Before(s.openUntil) {
+		return false, false, s.openUntil.Sub(now)
+	} else if s.probing {
+		return false, false, h.cooldown
+	}
+	s.probing = true
+	return true, true, 0
+}
+
+// acquire waits for the host's limiter and a free delivery slot. The returned
+// function releases the slot.
+//
+// If the delivery is a probe and no slot could be acquired, the probe is
+// abandoned so that a later delivery may probe the host instead.
+func (h *hosts) acquire(ctx context.Context, s *hostState, probe bool) (release func(), err error) {
+	defer func() {
+		if err != nil && probe {
+			s.mu.Lock()
+			s.probing = false
+			s.mu.Unlock()
+		}
+	}()
+	if s.limiter != nil {
+		if err = s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if s.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case s.sem <- struct{}{}:
+		return func() { <-s.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// report records the outcome of a delivery to the host, opening the circuit
+// after too many consecutive failures and closing it after a success.
+func (h *hosts) report(host string, s *hostState, now time.Time, err error) {
+	if h.threshold <= 0 {
+		return
+	}
+	s.mu.Lock()
+	wasOpen := s.open
+	s.probing = false
+	if err == nil {
+		s.failures = 0
+		s.open = false
+	} else {
+		s.failures++
+		if s.open || s.failures >= h.threshold {
+			s.open = true
+			s.openUntil = now.Add(h.cooldown)
+		}
+	}
+	isOpen := s.open
+	s.mu.Unlock()
+	if wasOpen != isOpen && h.onChange != nil {
+		h.onChange(host, isOpen)
+	}
+}
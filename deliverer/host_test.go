@@ -0,0 +1,147 @@
+package deliverer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/time/rate"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func drainErrors(pool *DelivererPool) {
+	go func() {
+		for range pool.Errors() {
+		}
+	}()
+}
+
+func TestDelivererPoolCircuitBreaker(t *testing.T) {
+	dead, err := url.Parse("https://dead.example/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthy, err := url.Parse("https://healthy.example/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var changes []string
+	recovered := false
+	healthySent := 0
+	testSendFn := func(b []byte, u *url.URL) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if u.Host == healthy.Host {
+			healthySent++
+			return nil
+		} else if !recovered {
+			return fmt.Errorf("expected")
+		}
+		return nil
+	}
+	pool := NewDelivererPool(DeliveryOptions{
+		InitialRetryTime:        time.Microsecond,
+		MaximumRetryTime:        time.Microsecond,
+		BackoffFactor:           2,
+		MaxRetries:              1000,
+		RateLimit:               rate.NewLimiter(rate.Inf, 1),
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerCooldown:  5 * time.Millisecond,
+		OnCircuitChange: func(host string, open bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, fmt.Sprintf("%s=%v", host, open))
+		},
+	})
+	defer pool.Stop()
+	drainErrors(pool)
+	pool.Do(testBytes, dead, testSendFn)
+	time.Sleep(2 * time.Millisecond)
+	pool.Do(testBytes, healthy, testSendFn)
+	time.Sleep(2 * time.Millisecond)
+	mu.Lock()
+	if healthySent != 1 {
+		t.Fatalf("want: 1 delivery to healthy host, got %d", healthySent)
+	} else if len(changes) != 1 || changes[0] != "dead.example=true" {
+		t.Fatalf("want: [dead.example=true], got %v", changes)
+	}
+	recovered = true
+	mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 2 || changes[1] != "dead.example=false" {
+		t.Fatalf("want: [dead.example=true dead.example=false], got %v", changes)
+	}
+}
+
+func TestHostsAbandonedProbe(t *testing.T) {
+	h := newHosts(DeliveryOptions{
+		MaxConcurrentPerHost:    1,
+		CircuitBreakerThreshold: 1,
+		CircuitBreakerCooldown:  time.Minute,
+	}, nil)
+	s := h.get("dead.example")
+	now := time.Now()
+	h.report("dead.example", s, now, errors.New("expected"))
+	now = now.Add(time.Minute)
+	ok, probe, _ := h.allow(s, now)
+	if !ok || !probe {
+		t.Fatalf("want: probe allowed, got ok=%v probe=%v", ok, probe)
+	}
+	// Another delivery holds the only slot, and the pool is stopped while the
+	// probe waits for it.
+	s.sem <- struct{}{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := h.acquire(ctx, s, probe); err == nil {
+		t.Fatalf("want: error acquiring slot, got nil")
+	}
+	<-s.sem
+	ok, probe, _ = h.allow(s, now)
+	if !ok || !probe {
+		t.Fatalf("want: new probe allowed, got ok=%v probe=%v", ok, probe)
+	}
+}
+
+func TestDelivererPoolMaxConcurrentPerHost(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight, sent := 0, 0, 0
+	testSendFn := func(b []byte, u *url.URL) error {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		inFlight--
+		sent++
+		mu.Unlock()
+		return nil
+	}
+	pool := NewDelivererPool(DeliveryOptions{
+		InitialRetryTime:     time.Microsecond,
+		MaximumRetryTime:     time.Microsecond,
+		BackoffFactor:        2,
+		MaxRetries:           1,
+		RateLimit:            rate.NewLimiter(rate.Inf, 1),
+		MaxConcurrentPerHost: 1,
+	})
+	defer pool.Stop()
+	for i := 0; i < 5; i++ {
+		pool.Do(testBytes, testURL, testSendFn)
+	}
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if sent != 5 {
+		t.Fatalf("want: 5 deliveries, got %d", sent)
+	} else if maxInFlight != 1 {
+		t.Fatalf("want: at most 1 delivery in flight, got %d", maxInFlight)
+	}
+}
@@ -0,0 +1,45 @@
+package deliverer
+
+import (
+	"net/url"
+	"time"
+)
+
+// Observer is notified of every step a DelivererPool takes, in order to
+// monitor outbound deliveries.
+//
+// Its methods are called from the pool's goroutines, so they must be safe for
+// concurrent use and should return quickly.
+type Observer interface {
+	// AttemptStarted is called before a delivery is attempted. The first
+	// attempt is number one.
+	AttemptStarted(to *url.URL, attempt int)
+	// AttemptFinished is called after a delivery is attempted, with how
+	// long it took. The statusCode is that of the peer's response when the
+	// attempt failed with a *pub.HttpStatusError, and zero otherwise.
+	AttemptFinished(to *url.URL, attempt int, latency time.Duration, statusCode int, err error)
+	// RetryScheduled is called when a failed delivery will be attempted
+	// again after the wait.
+	RetryScheduled(to *url.URL, attempt int, wait time.Duration)
+	// GaveUp is called when a delivery will no longer be attempted, either
+	// because it failed permanently or was tried the maximum number of
+	// times.
+	GaveUp(to *url.URL, attempts int, err error)
+	// CircuitChanged is called whenever the circuit breaker stops (open is
+	// true) or resumes (open is false) deliveries to a host.
+	CircuitChanged(host string, open bool)
+}
+
+// noopObserver ignores everything.
+type noopObserver struct{}
+
+func (noopObserver) AttemptStarted(to *url.URL, attempt int) {}
+
+func (noopObserver) AttemptFinished(to *url.URL, attempt int, latency time.Duration, statusCode int, err error) {
+}
+
+func (noopObserver) RetryScheduled(to *url.URL, attempt int, wait time.Duration) {}
+
+func (noopObserver) GaveUp(to *url.URL, attempts int, err error) {}
+
+func (noopObserver) CircuitChanged(host string, open bool) {}
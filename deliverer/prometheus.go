@@ -0,0 +1,233 @@
+package deliverer
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/go-fed/activity/pub"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the latency histograms.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+var _ Observer = &PrometheusObserver{}
+var _ pub.TransportObserver = &PrometheusObserver{}
+var _ http.Handler = &PrometheusObserver{}
+
+// PrometheusObserver counts deliveries and the requests of HttpSigTransports,
+// and serves the counts in the Prometheus text exposition format.
+//
+// It is an Observer for a DelivererPool, a pub.TransportObserver for an
+// HttpSigTransport, and an http.Handler for the metrics endpoint scraped by
+// Prometheus.
+type PrometheusObserver struct {
+	mu sync.Mutex
+	// Deliveries
+	attempts       map[string]uint64 // By result
+	inFlight       int64
+	attemptLatency *histogram
+	retries        uint64
+	gaveUp         uint64
+	// Hosts whose circuit is open. Only their number is served, so that
+	// the series do not grow with the number of peers.
+	circuitOpen map[string]bool
+	// Requests
+	requests       map[[2]string]uint64  // By method and result
+	requestLatency map[string]*histogram // By method
+}
+
+// NewPrometheusObserver returns a PrometheusObserver with all counts at zero.
+func NewPrometheusObserver() *PrometheusObserver {
+	return &PrometheusObserver{
+		attempts:       make(map[string]uint64),
+		attemptLatency: newHistogram(),
+		circuitOpen:    make(map[string]bool),
+		requests:       make(map[[2]string]uint64),
+		requestLatency: make(map[string]*histogram),
+	}
+}
+
+// AttemptStarted counts the delivery as in flight.
+func (p *PrometheusObserver) AttemptStarted(to *url.URL, attempt int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inFlight++
+}
+
+// AttemptFinished counts the attempt by its result, and observes its latency.
+func (p *PrometheusObserver) AttemptFinished(to *url.URL, attempt int, latency time.Duration, statusCode int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inFlight--
+	p.attempts[result(statusCode, err)]++
+	p.attemptLatency.observe(latency)
+}
+
+// RetryScheduled counts the retry.
+func (p *PrometheusObserver) RetryScheduled(to *url.URL, attempt int, wait time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.retries++
+}
+
+// GaveUp counts the undeliverable delivery.
+func (p *PrometheusObserver) GaveUp(to *url.URL, attempts int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.gaveUp++
+}
+
+// CircuitChanged records whether deliveries to the host are stopped.
+func (p *PrometheusObserver) CircuitChanged(host string, open bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if open {
+		p.circuitOpen[host] = true
+	} else {
+		delete(p.circuitOpen, host)
+	}
+}
+
+// RequestFinished counts the request by its method and result, and observes
+// its latency.
+func (p *PrometheusObserver) RequestFinished(method string, iri *url.URL, statusCode int, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if statusCode != 0 {
+		// The transport knows the status of successful requests too.
+		p.requests[[2]string{method, strconv.Itoa(statusCode)}]++
+	} else {
+		p.requests[[2]string{method, result(statusCode, err)}]++
+	}
+	h, ok := p.requestLatency[method]
+	if !ok {
+		h = newHistogram()
+		p.requestLatency[method] = h
+	}
+	h.observe(latency)
+}
+
+// result labels the outcome of a delivery attempt.
+func result(statusCode int, err error) string {
+	if err == nil {
+		return "success"
+	} else if statusCode != 0 {
+		return strconv.Itoa(statusCode)
+	}
+	return "error"
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (p *PrometheusObserver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var b bytes.Buffer
+	p.mu.Lock()
+	writeHeader(&b, "activitypub_delivery_attempts_total", "counter", "Delivery attempts by result.")
+	for _, k := range sortedKeys(p.attempts) {
+		fmt.Fprintf(&b, "activitypub_delivery_attempts_total{result=%s} %d\n", label(k), p.attempts[k])
+	}
+	writeHeader(&b, "activitypub_delivery_attempts_in_flight", "gauge", "Delivery attempts currently being made.")
+	fmt.Fprintf(&b, "activitypub_delivery_attempts_in_flight %d\n", p.inFlight)
+	writeHeader(&b, "activitypub_delivery_attempt_duration_seconds", "histogram", "Latency of delivery attempts.")
+	p.attemptLatency.write(&b, "activitypub_delivery_attempt_duration_seconds", "")
+	writeHeader(&b, "activitypub_delivery_retries_total", "counter", "Deliveries scheduled to be retried.")
+	fmt.Fprintf(&b, "activitypub_delivery_retries_total %d\n", p.retries)
+	writeHeader(&b, "activitypub_delivery_gave_up_total", "counter", "Deliveries no longer being attempted.")
+	fmt.Fprintf(&b, "activitypub_delivery_gave_up_total %d\n", p.gaveUp)
+	writeHeader(&b, "activitypub_delivery_circuits_open", "gauge", "Hosts whose deliveries are stopped by the circuit breaker.")
+	fmt.Fprintf(&b, "activitypub_delivery_circuits_open %d\n", len(p.circuitOpen))
+	writeHeader(&b, "activitypub_http_requests_total", "counter", "Signed requests made to peers by method and result.")
+	keys := make([][2]string, 0, len(p.requests))
+	for k := range p.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || (keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1])
+	})
+	for _, k := range keys {
+		fmt.Fprintf(&b, "activitypub_http_requests_total{method=%s,result=%s} %d\n", label(k[0]), label(k[1]), p.requests[k])
+	}
+	writeHeader(&b, "activitypub_http_request_duration_seconds", "histogram", "Latency of signed requests made to peers.")
+	methods := make([]string, 0, len(p.requestLatency))
+	for m := range p.requestLatency {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	for _, m := range methods {
+		p.requestLatency[m].write(&b, "activitypub_http_request_duration_seconds", "method="+label(m))
+	}
+	p.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(b.Bytes())
+}
+
+func writeHeader(b *bytes.Buffer, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// histogram counts observed latencies in cumulative buckets.
+type histogram struct {
+	counts []uint64 // Per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func newHistogram() *histogram {
+	return &histogram{
+		counts: make([]uint64, len(latencyBuckets)),
+	}
+}
+
+func (h *histogram) observe(d time.Duration) {
+	s := d.Seconds()
+	h.count++
+	h.sum += s
+	for i, le := range latencyBuckets {
+		if s <= le {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+// write writes the histogram's series, each with the labels.
+func (h *histogram) write(b *bytes.Buffer, name, labels string) {
+	sep := ""
+	if len(labels) > 0 {
+		sep = ","
+	}
+	var cumulative uint64
+	for i, le := range latencyBuckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(b, "%s_bucket{%s%sle=%s} %d\n", name, labels, sep, label(strconv.FormatFloat(le, 'g', -1, 64)), cumulative)
+	}
+	fmt.Fprintf(b, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+	braces := ""
+	if len(labels) > 0 {
+		braces = "{" + labels + "}"
+	}
+	fmt.Fprintf(b, "%s_sum%s %s\n", name, braces, strconv.FormatFloat(h.sum, 'g', -1, 64))
+	fmt.Fprintf(b, "%s_count%s %d\n", name, braces, h.count)
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label quotes and escapes a label value.
+func label(v string) string {
+	return `"` + labelEscaper.Replace(v) + `"`
+}
@@ -0,0 +1,55 @@
+package deliverer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusObserver(t *testing.T) {
+	p := NewPrometheusObserver()
+	p.AttemptStarted(testURL, 1)
+	p.AttemptFinished(testURL, 1, 200*time.Millisecond, 503, errors.New("expected"))
+	p.RetryScheduled(testURL, 1, time.Second)
+	p.AttemptStarted(testURL, 2)
+	p.AttemptFinished(testURL, 2, 2*time.Second, 0, nil)
+	p.CircuitChanged("example.com", true)
+	p.CircuitChanged("example.net", true)
+	p.CircuitChanged("example.net", false)
+	p.RequestFinished("POST", testURL, 202, 20*time.Millisecond, nil)
+	p.RequestFinished("GET", testURL, 0, time.Second, errors.New("expected"))
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE activitypub_delivery_attempts_total counter\n",
+		`activitypub_delivery_attempts_total{result="503"} 1` + "\n",
+		`activitypub_delivery_attempts_total{result="success"} 1` + "\n",
+		"activitypub_delivery_attempts_in_flight 0\n",
+		`activitypub_delivery_attempt_duration_seconds_bucket{le="0.1"} 0` + "\n",
+		`activitypub_delivery_attempt_duration_seconds_bucket{le="0.25"} 1` + "\n",
+		`activitypub_delivery_attempt_duration_seconds_bucket{le="+Inf"} 2` + "\n",
+		"activitypub_delivery_attempt_duration_seconds_sum 2.2\n",
+		"activitypub_delivery_attempt_duration_seconds_count 2\n",
+		"activitypub_delivery_retries_total 1\n",
+		"activitypub_delivery_gave_up_total 0\n",
+		"activitypub_delivery_circuits_open 1\n",
+		`activitypub_http_requests_total{method="GET",result="error"} 1` + "\n",
+		`activitypub_http_requests_total{method="POST",result="202"} 1` + "\n",
+		`activitypub_http_request_duration_seconds_bucket{method="POST",le="0.05"} 1` + "\n",
+		`activitypub_http_request_duration_seconds_count{method="GET"} 1` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("want: %q, got %s", want, body)
+		}
+	}
+}
+
+func TestPrometheusLabelEscaping(t *testing.T) {
+	if got, want := label("a\"b\\c\nd"), `"a\"b\\c\nd"`; got != want {
+		t.Fatalf("want: %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,388 @@
+package jsonld
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// activeContext is the result of processing the '@context' values in scope.
+type activeContext struct {
+	base     *url.URL
+	vocab    string
+	language string
+	// terms maps each term to its definition. A term mapped to nil was
+	// explicitly undefined, so it does not expand with the vocabulary.
+	terms map[string]*termDefinition
+}
+
+// termDefinition is the definition of a term in an activeContext.
+type termDefinition struct {
+	id        string
+	reverse   bool
+	typ       string
+	container string
+	// language is the default language of the term's string values, if
+	// hasLanguage. An empty language removes the context's default.
+	language    string
+	hasLanguage bool
+}
+
+func newActiveContext() *activeContext {
+	return &activeContext{terms: make(map[string]*termDefinition)}
+}
+
+func (a *activeContext) clone() *activeContext {
+	c := &activeContext{
+		base:     a.base,
+		vocab:    a.vocab,
+		language: a.language,
+		terms:    make(map[string]*termDefinition, len(a.terms)),
+	}
+	for k, v := range a.terms {
+		c.terms[k] = v
+	}
+	return c
+}
+
+// term returns the definition of the term, or nil if it has none.
+func (a *activeContext) term(t string) *termDefinition {
+	return a.terms[t]
+}
+
+// container returns the container mapping of the term, if any.
+func (a *activeContext) container(t string) string {
+	if d := a.terms[t]; d != nil {
+		return d.container
+	}
+	return ""
+}
+
+// processContext returns the result of applying the local context to the
+// active context, following the JSON-LD 1.0 Context Processing algorithm.
+// The remote contexts already being processed are tracked to detect cycles.
+func (p *processor) processContext(active *activeContext, local interface{}, remote []string) (*activeContext, error) {
+	result := active.clone()
+	contexts, ok := local.([]interface{})
+	if !ok {
+		contexts = []interface{}{local}
+	}
+	for _, ctx := range contexts {
+		switch v := ctx.(type) {
+		case nil:
+			result = newActiveContext()
+			result.base = active.base
+			continue
+		case string:
+			iri := v
+			if result.base != nil {
+				if u, err := result.base.Parse(v); err == nil {
+					iri = u.String()
+				}
+			}
+			for _, r := range remote {
+				if r == iri {
+					return nil, fmt.Errorf("recursive context inclusion: %s", iri)
+				}
+			}
+			doc, err := p.loadContext(iri)
+			if err != nil {
+				return nil, err
+			}
+			m, ok := doc.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid remote context: %s", iri)
+			}
+			inner, ok := m[keywordContext]
+			if !ok {
+				return nil, fmt.Errorf("invalid remote context: %s", iri)
+			}
+			if result, err = p.processContext(result, inner, append(remote[:len(remote):len(remote)], iri)); err != nil {
+				return nil, err
+			}
+			continue
+		case map[string]interface{}:
+			if err := p.processLocalContext(result, v, len(remote) == 0); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid local context: %v", ctx)
+		}
+	}
+	return result, nil
+}
+
+// processLocalContext applies a context definition to the result. The '@base'
+// of remote contexts is ignored.
+func (p *processor) processLocalContext(result *activeContext, ctx map[string]interface{}, allowBase bool) error {
+	if v, ok := ctx[keywordBase]; ok && allowBase {
+		switch b := v.(type) {
+		case nil:
+			result.base = nil
+		case string:
+			u, err := url.Parse(b)
+			if err != nil {
+				return fmt.Errorf("invalid base IRI: %v", b)
+			}
+			if u.IsAbs() {
+				result.base = u
+			} else if result.base != nil {
+				result.base = result.base.ResolveReference(u)
+			} else {
+				return fmt.Errorf("invalid base IRI: %v", b)
+			}
+		default:
+			return fmt.Errorf("invalid base IRI: %v", v)
+		}
+	}
+	if v, ok := ctx[keywordVocab]; ok {
+		switch vocab := v.(type) {
+		case nil:
+			result.vocab = ""
+		case string:
+			if !isAbsoluteIRI(vocab) && !isBlankNode(vocab) {
+				return fmt.Errorf("invalid vocab mapping: %v", vocab)
+			}
+			result.vocab = vocab
+		default:
+			return fmt.Errorf("invalid vocab mapping: %v", v)
+		}
+	}
+	if v, ok := ctx[keywordLanguage]; ok {
+		switch lang := v.(type) {
+		case nil:
+			result.language = ""
+		case string:
+			result.language = strings.ToLower(lang)
+		default:
+			return fmt.Errorf("invalid default language: %v", v)
+		}
+	}
+	defined := make(map[string]bool)
+	for _, term := range sortedKeys(ctx) {
+		if term == keywordBase || term == keywordVocab || term == keywordLanguage {
+			continue
+		}
+		if err := p.createTermDefinition(result, ctx, term, defined); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTermDefinition defines the term of the local context in the active
+// context. The defined map tracks the terms whose definitions are complete,
+// mapping to false those in progress, in order to detect cycles.
+func (p *processor) createTermDefinition(active *activeContext, local map[string]interface{}, term string, defined map[string]bool) error {
+	if done, ok := defined[term]; ok {
+		if done {
+			return nil
+		}
+		return fmt.Errorf("cyclic IRI mapping: %s", term)
+	}
+	defined[term] = false
+	if isKeyword(term) {
+		return fmt.Errorf("keyword redefinition: %s", term)
+	}
+	delete(active.terms, term)
+	value := local[term]
+	if m, ok := value.(map[string]interface{}); ok {
+		if id, ok := m[keywordID]; ok && id == nil {
+			value = nil
+		}
+	}
+	if value == nil {
+		active.terms[term] = nil
+		defined[term] = true
+		return nil
+	}
+	if s, ok := value.(string); ok {
+		value = map[string]interface{}{keywordID: s}
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid term definition: %s", term)
+	}
+	def := &termDefinition{}
+	if v, ok := m[keywordType]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("invalid type mapping: %s", term)
+		}
+		typ, _, err := p.expandIRI(active, s, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		if typ != keywordID && typ != keywordVocab && !isAbsoluteIRI(typ) {
+			return fmt.Errorf("invalid type mapping: %s", term)
+		}
+		def.typ = typ
+	}
+	if v, ok := m[keywordReverse]; ok {
+		if _, ok := m[keywordID]; ok {
+			return fmt.Errorf("invalid reverse property: %s", term)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("invalid IRI mapping: %s", term)
+		}
+		id, _, err := p.expandIRI(active, s, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		if !isAbsoluteIRI(id) && !isBlankNode(id) {
+			return fmt.Errorf("invalid IRI mapping: %s", term)
+		}
+		def.id = id
+		if c, ok := m[keywordContainer]; ok {
+			if c != nil && c != keywordSet && c != keywordIndex {
+				return fmt.Errorf("invalid reverse property: %s", term)
+			}
+			def.container, _ = c.(string)
+		}
+		def.reverse = true
+		active.terms[term] = def
+		defined[term] = true
+		return nil
+	}
+	if v, ok := m[keywordID]; ok && v != term {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("invalid IRI mapping: %s", term)
+		}
+		id, _, err := p.expandIRI(active, s, false, true, local, defined)
+		if err != nil {
+			return err
+		}
+		if !isKeyword(id) && !isAbsoluteIRI(id) && !isBlankNode(id) {
+			return fmt.Errorf("invalid IRI mapping: %s", term)
+		} else if id == keywordContext {
+			return fmt.Errorf("invalid keyword alias: %s", term)
+		}
+		def.id = id
+	} else if i := strings.Index(term, ":"); i >= 0 {
+		prefix, suffix := term[:i], term[i+1:]
+		if _, ok := local[prefix]; ok {
+			if err := p.createTermDefinition(active, local, prefix, defined); err != nil {
+				return err
+			}
+		}
+		if d := active.terms[prefix]; d != nil {
+			def.id = d.id + suffix
+		} else {
+			def.id = term
+		}
+	} else if len(active.vocab) > 0 {
+		def.id = active.vocab + term
+	} else {
+		return fmt.Errorf("invalid IRI mapping: %s", term)
+	}
+	if v, ok := m[keywordContainer]; ok {
+		switch v {
+		case keywordList, keywordSet, keywordIndex, keywordLanguage:
+			def.container = v.(string)
+		default:
+			return fmt.Errorf("invalid container mapping: %s", term)
+		}
+	}
+	if v, ok := m[keywordLanguage]; ok {
+		if _, ok := m[keywordType]; !ok {
+			switch lang := v.(type) {
+			case nil:
+				def.hasLanguage = true
+			case string:
+				def.language = strings.ToLower(lang)
+				def.hasLanguage = true
+			default:
+				return fmt.Errorf("invalid language mapping: %s", term)
+			}
+		}
+	}
+	active.terms[term] = def
+	defined[term] = true
+	return nil
+}
+
+// expandIRI expands a value to an absolute IRI, blank node identifier, or
+// keyword. Terms are expanded if vocab is true, and relative IRIs resolved
+// against the base if documentRelative is true. While processing a local
+// context, its terms are defined on demand.
+//
+// It returns false if the value expands to null.
+func (p *processor) expandIRI(active *activeContext, value string, documentRelative, vocab bool, local map[string]interface{}, defined map[string]bool) (string, bool, error) {
+	if isKeyword(value) {
+		return value, true, nil
+	}
+	if local != nil {
+		if _, ok := local[value]; ok && !defined[value] {
+			if err := p.createTermDefinition(active, local, value, defined); err != nil {
+				return "", false, err
+			}
+		}
+	}
+	if vocab {
+		if d, ok := active.terms[value]; ok {
+			if d == nil {
+				return "", false, nil
+			}
+			return d.id, true, nil
+		}
+	}
+	if i := strings.Index(value, ":"); i >= 0 {
+		prefix, suffix := value[:i], value[i+1:]
+		if prefix == "_" || strings.HasPrefix(suffix, "//") {
+			return value, true, nil
+		}
+		if local != nil {
+			if _, ok := local[prefix]; ok && !defined[prefix] {
+				if err := p.createTermDefinition(active, local, prefix, defined); err != nil {
+					return "", false, err
+				}
+			}
+		}
+		if d := active.terms[prefix]; d != nil {
+			return d.id + suffix, true, nil
+		}
+		return value, true, nil
+	}
+	if vocab && len(active.vocab) > 0 {
+		return active.vocab + value, true, nil
+	}
+	if documentRelative && active.base != nil {
+		if u, err := active.base.Parse(value); err == nil {
+			return u.String(), true, nil
+		}
+	}
+	return value, true, nil
+}
+
+// isAbsoluteIRI determines whether the value has a scheme.
+func isAbsoluteIRI(v string) bool {
+	i := strings.Index(v, ":")
+	if i <= 0 || isBlankNode(v) {
+		return false
+	}
+	for j, r := range v[:i] {
+		isAlpha := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isAlpha && (j == 0 || !((r >= '0' && r <= '9') || r == '+' || r == '-' || r == '.')) {
+			return false
+		}
+	}
+	return true
+}
+
+// isBlankNode determines whether the value is a blank node identifier.
+func isBlankNode(v string) bool {
+	return strings.HasPrefix(v, "_:")
+}
+
+// sortedKeys returns the keys of the map in code point order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,422 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// expandDocument expands the document into an array of node objects,
+// following the JSON-LD 1.0 Expansion algorithm.
+func (p *processor) expandDocument(doc interface{}) ([]interface{}, error) {
+	expanded, err := p.expand(newActiveContext(), "", doc)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := expanded.(map[string]interface{}); ok && len(m) == 1 {
+		if g, ok := m[keywordGraph]; ok {
+			expanded = g
+		}
+	}
+	if expanded == nil {
+		return []interface{}{}, nil
+	}
+	return asArray(expanded), nil
+}
+
+// expand expands the element in the active context, as the value of the active
+// property. An empty active property is null.
+func (p *processor) expand(active *activeContext, activeProperty string, element interface{}) (interface{}, error) {
+	switch v := element.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return p.expandArray(active, activeProperty, v)
+	case map[string]interface{}:
+		return p.expandObject(active, activeProperty, v)
+	default:
+		if !isScalar(v) {
+			return nil, fmt.Errorf("invalid JSON value: %T", v)
+		}
+		if len(activeProperty) == 0 || activeProperty == keywordGraph {
+			return nil, nil
+		}
+		return p.expandValue(active, activeProperty, v)
+	}
+}
+
+// expandArray expands each item of the array, flattening nested arrays.
+func (p *processor) expandArray(active *activeContext, activeProperty string, a []interface{}) (interface{}, error) {
+	result := []interface{}{}
+	isList := activeProperty == keywordList || active.container(activeProperty) == keywordList
+	for _, item := range a {
+		expanded, err := p.expand(active, activeProperty, item)
+		if err != nil {
+			return nil, err
+		}
+		if isList {
+			if _, ok := expanded.([]interface{}); ok || isListObject(expanded) {
+				return nil, fmt.Errorf("list of lists")
+			}
+		}
+		if items, ok := expanded.([]interface{}); ok {
+			result = append(result, items...)
+		} else if expanded != nil {
+			result = append(result, expanded)
+		}
+	}
+	return result, nil
+}
+
+// expandObject expands a JSON object into a node, value, list, or set object.
+func (p *processor) expandObject(active *activeContext, activeProperty string, m map[string]interface{}) (interface{}, error) {
+	if ctx, ok := m[keywordContext]; ok {
+		var err error
+		if active, err = p.processContext(active, ctx, nil); err != nil {
+			return nil, err
+		}
+	}
+	result := make(map[string]interface{})
+	for _, key := range sortedKeys(m) {
+		value := m[key]
+		if key == keywordContext {
+			continue
+		}
+		property, ok, err := p.expandIRI(active, key, false, true, nil, nil)
+		if err != nil {
+			return nil, err
+		} else if !ok || (!strings.Contains(property, ":") && !isKeyword(property)) {
+			continue
+		}
+		if isKeyword(property) {
+			if activeProperty == keywordReverse {
+				return nil, fmt.Errorf("invalid reverse property map")
+			} else if _, ok := result[property]; ok {
+				return nil, fmt.Errorf("colliding keywords: %s", property)
+			}
+			if err := p.expandKeyword(active, activeProperty, property, value, result); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		def := active.term(key)
+		container := active.container(key)
+		var expanded interface{}
+		if vm, ok := value.(map[string]interface{}); ok && container == keywordLanguage {
+			expanded, err = expandLanguageMap(vm)
+		} else if ok && container == keywordIndex {
+			expanded, err = p.expandIndexMap(active, key, vm)
+		} else {
+			expanded, err = p.expand(active, key, value)
+		}
+		if err != nil {
+			return nil, err
+		} else if expanded == nil {
+			continue
+		}
+		if container == keywordList && !isListObject(expanded) {
+			expanded = map[string]interface{}{keywordList: asArray(expanded)}
+		}
+		if def != nil && def.reverse {
+			reverseMap, _ := result[keywordReverse].(map[string]interface{})
+			if reverseMap == nil {
+				reverseMap = make(map[string]interface{})
+				result[keywordReverse] = reverseMap
+			}
+			for _, item := range asArray(expanded) {
+				if isValueObject(item) || isListObject(item) {
+					return nil, fmt.Errorf("invalid reverse property value")
+				}
+				addValue(reverseMap, property, item)
+			}
+			continue
+		}
+		for _, item := range asArray(expanded) {
+			addValue(result, property, item)
+		}
+	}
+	return p.finishObject(activeProperty, result)
+}
+
+// expandKeyword expands the value of a keyword, setting it on the result.
+func (p *processor) expandKeyword(active *activeContext, activeProperty, keyword string, value interface{}, result map[string]interface{}) error {
+	var expanded interface{}
+	switch keyword {
+	case keywordID:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid @id value")
+		}
+		id, _, err := p.expandIRI(active, s, true, false, nil, nil)
+		if err != nil {
+			return err
+		}
+		expanded = id
+	case keywordType:
+		switch t := value.(type) {
+		case string:
+			typ, _, err := p.expandIRI(active, t, true, true, nil, nil)
+			if err != nil {
+				return err
+			}
+			expanded = typ
+		case []interface{}:
+			types := make([]interface{}, 0, len(t))
+			for _, item := range t {
+				s, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("invalid type value")
+				}
+				typ, _, err := p.expandIRI(active, s, true, true, nil, nil)
+				if err != nil {
+					return err
+				}
+				types = append(types, typ)
+			}
+			expanded = types
+		default:
+			return fmt.Errorf("invalid type value")
+		}
+	case keywordGraph:
+		var err error
+		if expanded, err = p.expand(active, keywordGraph, value); err != nil {
+			return err
+		}
+	case keywordValue:
+		if value != nil && !isScalar(value) {
+			return fmt.Errorf("invalid value object value")
+		}
+		// A null @value is kept, so the value object becomes null.
+		result[keywordValue] = value
+		return nil
+	case keywordLanguage:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid language-tagged string")
+		}
+		expanded = strings.ToLower(s)
+	case keywordIndex:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid @index value")
+		}
+		expanded = s
+	case keywordList:
+		if len(activeProperty) == 0 || activeProperty == keywordGraph {
+			return nil
+		}
+		var err error
+		if expanded, err = p.expand(active, activeProperty, value); err != nil {
+			return err
+		} else if isListObject(expanded) {
+			return fmt.Errorf("list of lists")
+		} else if expanded == nil {
+			expanded = []interface{}{}
+		}
+		expanded = asArray(expanded)
+	case keywordSet:
+		var err error
+		if expanded, err = p.expand(active, activeProperty, value); err != nil {
+			return err
+		}
+	case keywordReverse:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("invalid @reverse value")
+		}
+		e, err := p.expand(active, keywordReverse, value)
+		if err != nil {
+			return err
+		}
+		em, _ := e.(map[string]interface{})
+		if inner, ok := em[keywordReverse].(map[string]interface{}); ok {
+			for property, items := range inner {
+				for _, item := range asArray(items) {
+					addValue(result, property, item)
+				}
+			}
+		}
+		var reverseMap map[string]interface{}
+		for property, items := range em {
+			if property == keywordReverse {
+				continue
+			}
+			if reverseMap == nil {
+				reverseMap, _ = result[keywordReverse].(map[string]interface{})
+				if reverseMap == nil {
+					reverseMap = make(map[string]interface{})
+					result[keywordReverse] = reverseMap
+				}
+			}
+			for _, item := range asArray(items) {
+				if isValueObject(item) || isListObject(item) {
+					return fmt.Errorf("invalid reverse property value")
+				}
+				addValue(reverseMap, property, item)
+			}
+		}
+		return nil
+	default:
+		// Other keywords, such as @base, are ignored in a node.
+		return nil
+	}
+	if expanded != nil {
+		result[keyword] = expanded
+	}
+	return nil
+}
+
+// finishObject validates and simplifies an expanded object.
+func (p *processor) finishObject(activeProperty string, result map[string]interface{}) (interface{}, error) {
+	if v, ok := result[keywordValue]; ok {
+		for k := range result {
+			if k != keywordValue && k != keywordLanguage && k != keywordType && k != keywordIndex {
+				return nil, fmt.Errorf("invalid value object")
+			}
+		}
+		_, hasLanguage := result[keywordLanguage]
+		t, hasType := result[keywordType]
+		if hasLanguage && hasType {
+			return nil, fmt.Errorf("invalid value object")
+		} else if v == nil {
+			return nil, nil
+		} else if _, ok := v.(string); !ok && hasLanguage {
+			return nil, fmt.Errorf("invalid language-tagged value")
+		} else if s, ok := t.(string); hasType && (!ok || !isAbsoluteIRI(s)) {
+			return nil, fmt.Errorf("invalid typed value")
+		}
+	} else if t, ok := result[keywordType]; ok {
+		result[keywordType] = asArray(t)
+	} else if _, hasSet := result[keywordSet]; hasSet || isListObject(result) {
+		for k := range result {
+			if k != keywordSet && k != keywordList && k != keywordIndex {
+				return nil, fmt.Errorf("invalid set or list object")
+			}
+		}
+		if hasSet {
+			return result[keywordSet], nil
+		}
+	}
+	if _, ok := result[keywordLanguage]; ok && len(result) == 1 {
+		return nil, nil
+	}
+	if len(activeProperty) == 0 || activeProperty == keywordGraph {
+		_, hasID := result[keywordID]
+		if len(result) == 0 || isValueObject(result) || isListObject(result) {
+			return nil, nil
+		} else if hasID && len(result) == 1 {
+			return nil, nil
+		}
+	}
+	return result, nil
+}
+
+// expandValue expands a scalar value of the active property into a value
+// object, or a node reference if the property's values are IRIs.
+func (p *processor) expandValue(active *activeContext, activeProperty string, value interface{}) (interface{}, error) {
+	def := active.term(activeProperty)
+	if s, ok := value.(string); ok && def != nil {
+		if def.typ == keywordID {
+			id, _, err := p.expandIRI(active, s, true, false, nil, nil)
+			return map[string]interface{}{keywordID: id}, err
+		} else if def.typ == keywordVocab {
+			id, _, err := p.expandIRI(active, s, true, true, nil, nil)
+			return map[string]interface{}{keywordID: id}, err
+		}
+	}
+	result := map[string]interface{}{keywordValue: value}
+	if def != nil && def.typ != "" && def.typ != keywordID && def.typ != keywordVocab {
+		result[keywordType] = def.typ
+	} else if _, ok := value.(string); ok {
+		language := active.language
+		if def != nil && def.hasLanguage {
+			language = def.language
+		}
+		if len(language) > 0 {
+			result[keywordLanguage] = language
+		}
+	}
+	return result, nil
+}
+
+// expandLanguageMap expands the value of a term whose container is @language.
+func expandLanguageMap(m map[string]interface{}) (interface{}, error) {
+	result := []interface{}{}
+	for _, language := range sortedKeys(m) {
+		for _, item := range asArray(m[language]) {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid language map value")
+			}
+			result = append(result, map[string]interface{}{
+				keywordValue:    s,
+				keywordLanguage: strings.ToLower(language),
+			})
+		}
+	}
+	return result, nil
+}
+
+// expandIndexMap expands the value of a term whose container is @index.
+func (p *processor) expandIndexMap(active *activeContext, activeProperty string, m map[string]interface{}) (interface{}, error) {
+	result := []interface{}{}
+	for _, index := range sortedKeys(m) {
+		expanded, err := p.expand(active, activeProperty, asArray(m[index]))
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range asArray(expanded) {
+			if im, ok := item.(map[string]interface{}); ok {
+				if _, ok := im[keywordIndex]; !ok {
+					im[keywordIndex] = index
+				}
+			}
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// isScalar determines whether the decoded JSON value is a string, number, or
+// boolean.
+func isScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, bool, float64, json.Number, int, int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// isValueObject determines whether the value is an expanded value object.
+func isValueObject(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = m[keywordValue]
+	return ok
+}
+
+// isListObject determines whether the value is an expanded list object.
+func isListObject(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = m[keywordList]
+	return ok
+}
+
+// asArray returns the value if it is an array, or else an array of it.
+func asArray(v interface{}) []interface{} {
+	if a, ok := v.([]interface{}); ok {
+		return a
+	}
+	return []interface{}{v}
+}
+
+// addValue appends the value to the array of the property.
+func addValue(m map[string]interface{}, property string, value interface{}) {
+	existing, _ := m[property].([]interface{})
+	m[property] = append(existing, value)
+}
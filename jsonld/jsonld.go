@@ -0,0 +1,107 @@
+// Package jsonld canonicalizes JSON-LD documents with the URDNA2015 algorithm,
+// as required to create and verify Linked Data Signatures.
+//
+// Only the parts of JSON-LD 1.0 needed to convert a document into RDF are
+// implemented: context processing, expansion, and RDF serialization. Remote
+// contexts are never fetched from the network. Instead they are obtained from
+// a ContextLoader, and the ActivityStreams and W3ID security and identity
+// contexts are embedded.
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSON-LD keywords.
+const (
+	keywordBase      = "@base"
+	keywordContainer = "@container"
+	keywordContext   = "@context"
+	keywordGraph     = "@graph"
+	keywordID        = "@id"
+	keywordIndex     = "@index"
+	keywordLanguage  = "@language"
+	keywordList      = "@list"
+	keywordReverse   = "@reverse"
+	keywordSet       = "@set"
+	keywordType      = "@type"
+	keywordValue     = "@value"
+	keywordVocab     = "@vocab"
+)
+
+// isKeyword determines whether the value is a JSON-LD keyword.
+func isKeyword(v string) bool {
+	switch v {
+	case keywordBase, keywordContainer, keywordContext, keywordGraph,
+		keywordID, keywordIndex, keywordLanguage, keywordList,
+		keywordReverse, keywordSet, keywordType, keywordValue,
+		keywordVocab:
+		return true
+	default:
+		return false
+	}
+}
+
+// ContextLoader obtains the remote context document at the IRI, as decoded by
+// encoding/json. It must not fetch arbitrary IRIs from the network, since
+// the documents canonicalized come from peers.
+type ContextLoader func(iri string) (interface{}, error)
+
+// EmbeddedContexts is a ContextLoader of the ActivityStreams, W3ID security,
+// and W3ID identity contexts. It returns an error for any other IRI.
+func EmbeddedContexts(iri string) (interface{}, error) {
+	s, ok := embeddedContexts[iri]
+	if !ok {
+		return nil, fmt.Errorf("no embedded JSON-LD context: %s", iri)
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(s), &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// processor holds the state of a single canonicalization.
+type processor struct {
+	load ContextLoader
+	// contexts caches the remote contexts loaded.
+	contexts map[string]interface{}
+}
+
+// loadContext obtains the remote context, once.
+func (p *processor) loadContext(iri string) (interface{}, error) {
+	if doc, ok := p.contexts[iri]; ok {
+		return doc, nil
+	}
+	doc, err := p.load(iri)
+	if err != nil {
+		return nil, err
+	}
+	p.contexts[iri] = doc
+	return doc, nil
+}
+
+// Canonicalize converts the JSON-LD document into RDF and canonicalizes it
+// with the URDNA2015 algorithm, returning the sorted N-Quads.
+//
+// The document is decoded JSON, as by encoding/json, and is not modified. If
+// load is nil, EmbeddedContexts is used.
+func Canonicalize(doc interface{}, load ContextLoader) ([]byte, error) {
+	if load == nil {
+		load = EmbeddedContexts
+	}
+	p := &processor{
+		load:     load,
+		contexts: make(map[string]interface{}),
+	}
+	expanded, err := p.expandDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	dataset, err := toRDF(expanded)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(normalize(dataset)), nil
+}
@@ -0,0 +1,127 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{
+			name: "signature options",
+			doc: `{
+				"@context": "https://w3id.org/identity/v1",
+				"creator": "https://example.com/users/alice#main-key",
+				"created": "2020-01-01T00:00:00Z"
+			}`,
+			expected: `_:c14n0 <http://purl.org/dc/terms/created> "2020-01-01T00:00:00Z"^^<http://www.w3.org/2001/XMLSchema#dateTime> .
+_:c14n0 <http://purl.org/dc/terms/creator> <https://example.com/users/alice#main-key> .
+`,
+		},
+		{
+			name: "activity streams note",
+			doc: `{
+				"@context": "https://www.w3.org/ns/activitystreams",
+				"id": "https://example.com/notes/1",
+				"type": "Note",
+				"content": "hi \"there\"\n",
+				"to": ["https://www.w3.org/ns/activitystreams#Public"],
+				"unknown": "dropped"
+			}`,
+			expected: `<https://example.com/notes/1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://www.w3.org/ns/activitystreams#Note> .
+<https://example.com/notes/1> <https://www.w3.org/ns/activitystreams#content> "hi \"there\"\n" .
+<https://example.com/notes/1> <https://www.w3.org/ns/activitystreams#to> <https://www.w3.org/ns/activitystreams#Public> .
+`,
+		},
+		{
+			name: "literals",
+			doc: `{
+				"@context": {"v": "http://example.com/v", "@language": "en"},
+				"@id": "http://example.com/s",
+				"v": [1, 1.5, true, "hello", {"@value": "hallo", "@language": "de"}]
+			}`,
+			expected: `<http://example.com/s> <http://example.com/v> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.com/s> <http://example.com/v> "1.5E0"^^<http://www.w3.org/2001/XMLSchema#double> .
+<http://example.com/s> <http://example.com/v> "hallo"@de .
+<http://example.com/s> <http://example.com/v> "hello"@en .
+<http://example.com/s> <http://example.com/v> "true"^^<http://www.w3.org/2001/XMLSchema#boolean> .
+`,
+		},
+		{
+			name: "list",
+			doc: `{
+				"@context": "https://www.w3.org/ns/activitystreams",
+				"id": "https://example.com/outbox",
+				"orderedItems": ["https://example.com/a", "https://example.com/b"]
+			}`,
+			expected: `<https://example.com/outbox> <https://www.w3.org/ns/activitystreams#items> _:c14n1 .
+_:c14n0 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <https://example.com/b> .
+_:c14n0 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+_:c14n1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <https://example.com/a> .
+_:c14n1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:c14n0 .
+`,
+		},
+	}
+	for _, test := range tests {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(test.doc), &doc); err != nil {
+			t.Fatalf("(%q): %v", test.name, err)
+		}
+		actual, err := Canonicalize(doc, nil)
+		if err != nil {
+			t.Fatalf("(%q): %v", test.name, err)
+		} else if string(actual) != test.expected {
+			t.Fatalf("(%q): expected\n%s\ngot\n%s", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestCanonicalizeBlankNodeLabels(t *testing.T) {
+	docs := []string{
+		`{
+			"@context": {"p": {"@id": "http://example.com/p", "@type": "@id"}, "q": "http://example.com/q"},
+			"@graph": [
+				{"@id": "_:x", "p": "_:y", "q": "one"},
+				{"@id": "_:y", "p": "_:z"},
+				{"@id": "_:z", "p": "_:x"}
+			]
+		}`,
+		`{
+			"@context": {"p": {"@id": "http://example.com/p", "@type": "@id"}, "q": "http://example.com/q"},
+			"@graph": [
+				{"@id": "_:c", "p": "_:a"},
+				{"@id": "_:b", "p": "_:c", "q": "one"},
+				{"@id": "_:a", "p": "_:b"}
+			]
+		}`,
+	}
+	var expected string
+	for i, s := range docs {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(s), &doc); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := Canonicalize(doc, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if i == 0 {
+			expected = string(actual)
+		} else if string(actual) != expected {
+			t.Fatalf("expected\n%s\ngot\n%s", expected, actual)
+		}
+	}
+}
+
+func TestCanonicalizeUnknownContext(t *testing.T) {
+	doc := map[string]interface{}{
+		"@context": "https://example.com/context",
+		"name":     "unknown",
+	}
+	if _, err := Canonicalize(doc, nil); err == nil {
+		t.Fatalf("expected an error, got none")
+	}
+}
@@ -0,0 +1,485 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IRIs of the RDF and XML Schema vocabularies used when converting to RDF.
+const (
+	rdfFirst      = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	rdfRest       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	rdfNil        = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+	rdfType       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	rdfLangString = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+	xsdBoolean    = "http://www.w3.org/2001/XMLSchema#boolean"
+	xsdDouble     = "http://www.w3.org/2001/XMLSchema#double"
+	xsdInteger    = "http://www.w3.org/2001/XMLSchema#integer"
+	xsdString     = "http://www.w3.org/2001/XMLSchema#string"
+)
+
+// defaultGraph is the name of the default graph in a node map.
+const defaultGraph = "@default"
+
+// termKind is the kind of an RDF term.
+type termKind int
+
+const (
+	iriTerm termKind = iota
+	blankTerm
+	literalTerm
+	defaultGraphTerm
+)
+
+// rdfTerm is a subject, predicate, object, or graph name of a quad.
+type rdfTerm struct {
+	kind     termKind
+	value    string
+	datatype string
+	language string
+}
+
+// quad is an RDF statement in a named or the default graph.
+type quad struct {
+	subject   rdfTerm
+	predicate rdfTerm
+	object    rdfTerm
+	graph     rdfTerm
+}
+
+// newNode returns the IRI or blank node term of the identifier.
+func newNode(id string) rdfTerm {
+	if isBlankNode(id) {
+		return rdfTerm{kind: blankTerm, value: id}
+	}
+	return rdfTerm{kind: iriTerm, value: id}
+}
+
+// nquadEscaper escapes the characters of a literal that canonical N-Quads
+// escape.
+var nquadEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`)
+
+// nquad returns the N-Quads serialization of the term.
+func (t rdfTerm) nquad() string {
+	switch t.kind {
+	case iriTerm:
+		return "<" + t.value + ">"
+	case blankTerm:
+		return t.value
+	case literalTerm:
+		s := `"` + nquadEscaper.Replace(t.value) + `"`
+		if t.datatype == rdfLangString {
+			return s + "@" + t.language
+		} else if t.datatype != xsdString {
+			return s + "^^<" + t.datatype + ">"
+		}
+		return s
+	default:
+		return ""
+	}
+}
+
+// nquad returns the N-Quads serialization of the quad, ending in a newline.
+func (q quad) nquad() string {
+	s := q.subject.nquad() + " " + q.predicate.nquad() + " " + q.object.nquad()
+	if q.graph.kind != defaultGraphTerm {
+		s += " " + q.graph.nquad()
+	}
+	return s + " .\n"
+}
+
+// identifierIssuer issues blank node identifiers with a prefix and counter,
+// remembering the order in which existing identifiers were given them.
+type identifierIssuer struct {
+	prefix  string
+	counter int
+	issued  map[string]string
+	order   []string
+}
+
+func newIdentifierIssuer(prefix string) *identifierIssuer {
+	return &identifierIssuer{
+		prefix: prefix,
+		issued: make(map[string]string),
+	}
+}
+
+// issue returns the identifier issued for the existing one, issuing a new one
+// if necessary. An empty existing identifier always gets a new one.
+func (i *identifierIssuer) issue(existing string) string {
+	if id, ok := i.issued[existing]; ok && len(existing) > 0 {
+		return id
+	}
+	id := i.prefix + strconv.Itoa(i.counter)
+	i.counter++
+	if len(existing) > 0 {
+		i.issued[existing] = id
+		i.order = append(i.order, existing)
+	}
+	return id
+}
+
+// has determines whether an identifier was issued for the existing one.
+func (i *identifierIssuer) has(existing string) bool {
+	_, ok := i.issued[existing]
+	return ok
+}
+
+func (i *identifierIssuer) clone() *identifierIssuer {
+	c := &identifierIssuer{
+		prefix:  i.prefix,
+		counter: i.counter,
+		issued:  make(map[string]string, len(i.issued)),
+		order:   append([]string(nil), i.order...),
+	}
+	for k, v := range i.issued {
+		c.issued[k] = v
+	}
+	return c
+}
+
+// nodeMapper generates the node map of an expanded document, following the
+// JSON-LD 1.0 Node Map Generation algorithm.
+type nodeMapper struct {
+	graphs map[string]map[string]map[string]interface{}
+	issuer *identifierIssuer
+}
+
+// graph returns the nodes of the named graph, creating it if necessary.
+func (n *nodeMapper) graph(name string) map[string]map[string]interface{} {
+	g, ok := n.graphs[name]
+	if !ok {
+		g = make(map[string]map[string]interface{})
+		n.graphs[name] = g
+	}
+	return g
+}
+
+// generate adds the expanded element to the node map. The active subject is
+// the identifier of the node whose active property the element is a value of,
+// or the node reference of a reverse property. Values of a list are appended
+// to it instead.
+func (n *nodeMapper) generate(element interface{}, activeGraph string, activeSubject interface{}, activeProperty string, list map[string]interface{}) error {
+	if a, ok := element.([]interface{}); ok {
+		for _, item := range a {
+			if err := n.generate(item, activeGraph, activeSubject, activeProperty, list); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	elem, ok := element.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid expanded element: %v", element)
+	}
+	graph := n.graph(activeGraph)
+	subject, _ := activeSubject.(string)
+	if t, ok := elem[keywordType]; ok {
+		if s, ok := t.(string); ok && isBlankNode(s) {
+			elem[keywordType] = n.issuer.issue(s)
+		} else if types, ok := t.([]interface{}); ok {
+			relabeled := make([]interface{}, len(types))
+			for i, typ := range types {
+				if s, ok := typ.(string); ok && isBlankNode(s) {
+					typ = n.issuer.issue(s)
+				}
+				relabeled[i] = typ
+			}
+			elem[keywordType] = relabeled
+		}
+	}
+	if _, ok := elem[keywordValue]; ok {
+		if list == nil {
+			addUniqueValue(graph[subject], activeProperty, elem)
+		} else {
+			addValue(list, keywordList, elem)
+		}
+		return nil
+	}
+	if l, ok := elem[keywordList]; ok {
+		result := map[string]interface{}{keywordList: []interface{}{}}
+		if err := n.generate(l, activeGraph, activeSubject, activeProperty, result); err != nil {
+			return err
+		}
+		addValue(graph[subject], activeProperty, result)
+		return nil
+	}
+	var id string
+	if v, ok := elem[keywordID]; ok {
+		id, _ = v.(string)
+		delete(elem, keywordID)
+		if isBlankNode(id) {
+			id = n.issuer.issue(id)
+		}
+	} else {
+		id = n.issuer.issue("")
+	}
+	node, ok := graph[id]
+	if !ok {
+		node = map[string]interface{}{keywordID: id}
+		graph[id] = node
+	}
+	if ref, ok := activeSubject.(map[string]interface{}); ok {
+		addUniqueValue(node, activeProperty, ref)
+	} else if len(activeProperty) > 0 {
+		reference := map[string]interface{}{keywordID: id}
+		if list == nil {
+			addUniqueValue(graph[subject], activeProperty, reference)
+		} else {
+			addValue(list, keywordList, reference)
+		}
+	}
+	if t, ok := elem[keywordType]; ok {
+		for _, typ := range asArray(t) {
+			addUniqueValue(node, keywordType, typ)
+		}
+		delete(elem, keywordType)
+	}
+	if index, ok := elem[keywordIndex]; ok {
+		if existing, ok := node[keywordIndex]; ok && existing != index {
+			return fmt.Errorf("conflicting indexes")
+		}
+		node[keywordIndex] = index
+		delete(elem, keywordIndex)
+	}
+	if reverse, ok := elem[keywordReverse].(map[string]interface{}); ok {
+		referenced := map[string]interface{}{keywordID: id}
+		for _, property := range sortedKeys(reverse) {
+			for _, value := range asArray(reverse[property]) {
+				if err := n.generate(value, activeGraph, referenced, property, nil); err != nil {
+					return err
+				}
+			}
+		}
+		delete(elem, keywordReverse)
+	}
+	if g, ok := elem[keywordGraph]; ok {
+		if err := n.generate(g, id, nil, "", nil); err != nil {
+			return err
+		}
+		delete(elem, keywordGraph)
+	}
+	for _, property := range sortedKeys(elem) {
+		value := elem[property]
+		if isBlankNode(property) {
+			property = n.issuer.issue(property)
+		}
+		if _, ok := node[property]; !ok {
+			node[property] = []interface{}{}
+		}
+		if err := n.generate(value, activeGraph, id, property, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addUniqueValue appends the value to the array of the property, unless it is
+// already there.
+func addUniqueValue(m map[string]interface{}, property string, value interface{}) {
+	if m == nil {
+		return
+	}
+	existing, _ := m[property].([]interface{})
+	for _, v := range existing {
+		if reflect.DeepEqual(v, value) {
+			return
+		}
+	}
+	m[property] = append(existing, value)
+}
+
+// toRDF converts the expanded document into RDF quads, following the JSON-LD
+// 1.0 Deserialize JSON-LD to RDF algorithm. Relative IRIs, and properties
+// that are blank nodes, are dropped.
+func toRDF(expanded []interface{}) ([]quad, error) {
+	n := &nodeMapper{
+		graphs: map[string]map[string]map[string]interface{}{defaultGraph: {}},
+		issuer: newIdentifierIssuer("_:b"),
+	}
+	if err := n.generate(expanded, defaultGraph, nil, "", nil); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(n.graphs))
+	for name := range n.graphs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var dataset []quad
+	for _, name := range names {
+		graphName := rdfTerm{kind: defaultGraphTerm}
+		if name != defaultGraph {
+			if !isAbsoluteIRI(name) && !isBlankNode(name) {
+				continue
+			}
+			graphName = newNode(name)
+		}
+		graph := n.graphs[name]
+		subjects := make([]string, 0, len(graph))
+		for s := range graph {
+			subjects = append(subjects, s)
+		}
+		sort.Strings(subjects)
+		for _, s := range subjects {
+			if !isAbsoluteIRI(s) && !isBlankNode(s) {
+				continue
+			}
+			subject := newNode(s)
+			node := graph[s]
+			for _, property := range sortedKeys(node) {
+				values, _ := node[property].([]interface{})
+				if property == keywordType {
+					for _, t := range values {
+						typ, _ := t.(string)
+						dataset = append(dataset, quad{
+							subject:   subject,
+							predicate: rdfTerm{kind: iriTerm, value: rdfType},
+							object:    newNode(typ),
+							graph:     graphName,
+						})
+					}
+					continue
+				} else if isKeyword(property) || !isAbsoluteIRI(property) {
+					continue
+				}
+				predicate := rdfTerm{kind: iriTerm, value: property}
+				for _, item := range values {
+					var object rdfTerm
+					if isListObject(item) {
+						l, _ := item.(map[string]interface{})[keywordList].([]interface{})
+						object = n.listToRDF(l, graphName, &dataset)
+					} else if o, ok := objectToRDF(item); ok {
+						object = o
+					} else {
+						continue
+					}
+					dataset = append(dataset, quad{
+						subject:   subject,
+						predicate: predicate,
+						object:    object,
+						graph:     graphName,
+					})
+				}
+			}
+		}
+	}
+	return dataset, nil
+}
+
+// listToRDF adds the quads of an RDF collection of the items to the dataset,
+// returning its head.
+func (n *nodeMapper) listToRDF(items []interface{}, graphName rdfTerm, dataset *[]quad) rdfTerm {
+	if len(items) == 0 {
+		return rdfTerm{kind: iriTerm, value: rdfNil}
+	}
+	nodes := make([]rdfTerm, len(items))
+	for i := range items {
+		nodes[i] = rdfTerm{kind: blankTerm, value: n.issuer.issue("")}
+	}
+	for i, item := range items {
+		if object, ok := objectToRDF(item); ok {
+			*dataset = append(*dataset, quad{
+				subject:   nodes[i],
+				predicate: rdfTerm{kind: iriTerm, value: rdfFirst},
+				object:    object,
+				graph:     graphName,
+			})
+		}
+		rest := rdfTerm{kind: iriTerm, value: rdfNil}
+		if i < len(items)-1 {
+			rest = nodes[i+1]
+		}
+		*dataset = append(*dataset, quad{
+			subject:   nodes[i],
+			predicate: rdfTerm{kind: iriTerm, value: rdfRest},
+			object:    rest,
+			graph:     graphName,
+		})
+	}
+	return nodes[0]
+}
+
+// objectToRDF converts a node reference or value object into an RDF term. It
+// returns false for a reference to a relative IRI.
+func objectToRDF(item interface{}) (rdfTerm, bool) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return rdfTerm{}, false
+	}
+	if id, ok := m[keywordID].(string); ok {
+		if !isAbsoluteIRI(id) && !isBlankNode(id) {
+			return rdfTerm{}, false
+		}
+		return newNode(id), true
+	}
+	datatype, _ := m[keywordType].(string)
+	literal := rdfTerm{kind: literalTerm, datatype: datatype}
+	switch v := m[keywordValue].(type) {
+	case bool:
+		literal.value = strconv.FormatBool(v)
+		if len(datatype) == 0 {
+			literal.datatype = xsdBoolean
+		}
+	case string:
+		literal.value = v
+		if language, ok := m[keywordLanguage].(string); ok {
+			literal.datatype = rdfLangString
+			literal.language = language
+		} else if len(datatype) == 0 {
+			literal.datatype = xsdString
+		}
+	default:
+		f, ok := toFloat(v)
+		if !ok {
+			return rdfTerm{}, false
+		}
+		if f != math.Trunc(f) || math.Abs(f) >= 1e21 || datatype == xsdDouble {
+			literal.value = canonicalDouble(f)
+			if len(datatype) == 0 {
+				literal.datatype = xsdDouble
+			}
+		} else {
+			literal.value = strconv.FormatFloat(f, 'f', 0, 64)
+			if len(datatype) == 0 {
+				literal.datatype = xsdInteger
+			}
+		}
+	}
+	return literal, true
+}
+
+// toFloat converts a decoded JSON number to a float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// trailingZeros matches the zeros ending a mantissa, after its last
+// significant digit.
+var trailingZeros = regexp.MustCompile(`(\d)0*$`)
+
+// canonicalDouble formats the number as an xsd:double canonical value, with
+// an exponent and no trailing zeros, such as 1.1E0.
+func canonicalDouble(f float64) string {
+	s := strconv.FormatFloat(f, 'e', 15, 64)
+	i := strings.IndexByte(s, 'e')
+	exp, _ := strconv.Atoi(s[i+1:])
+	return trailingZeros.ReplaceAllString(s[:i], "$1") + "E" + strconv.Itoa(exp)
+}
@@ -0,0 +1,274 @@
+package jsonld
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// canonicalizer holds the state of the URDNA2015 algorithm over a dataset.
+type canonicalizer struct {
+	// quads maps each blank node identifier to the quads mentioning it.
+	quads     map[string][]quad
+	canonical *identifierIssuer
+	// firstDegree caches the first degree hash of each blank node.
+	firstDegree map[string]string
+}
+
+// normalize canonicalizes the blank nodes of the dataset with the URDNA2015
+// algorithm, returning its sorted N-Quads.
+func normalize(dataset []quad) string {
+	c := &canonicalizer{
+		quads:       make(map[string][]quad),
+		canonical:   newIdentifierIssuer("_:c14n"),
+		firstDegree: make(map[string]string),
+	}
+	for _, q := range dataset {
+		for _, t := range []rdfTerm{q.subject, q.object, q.graph} {
+			if t.kind == blankTerm {
+				if qs := c.quads[t.value]; len(qs) == 0 || !sameQuad(qs[len(qs)-1], q) {
+					c.quads[t.value] = append(qs, q)
+				}
+			}
+		}
+	}
+	nonNormalized := make(map[string]bool, len(c.quads))
+	for id := range c.quads {
+		nonNormalized[id] = true
+	}
+	var hashToBlankNodes map[string][]string
+	for simple := true; simple; {
+		simple = false
+		hashToBlankNodes = make(map[string][]string)
+		for _, id := range sortedSet(nonNormalized) {
+			h := c.hashFirstDegreeQuads(id)
+			hashToBlankNodes[h] = append(hashToBlankNodes[h], id)
+		}
+		for _, h := range sortedHashes(hashToBlankNodes) {
+			ids := hashToBlankNodes[h]
+			if len(ids) > 1 {
+				continue
+			}
+			c.canonical.issue(ids[0])
+			delete(nonNormalized, ids[0])
+			delete(hashToBlankNodes, h)
+			simple = true
+		}
+	}
+	for _, h := range sortedHashes(hashToBlankNodes) {
+		type hashPath struct {
+			hash   string
+			issuer *identifierIssuer
+		}
+		var paths []hashPath
+		for _, id := range hashToBlankNodes[h] {
+			if c.canonical.has(id) {
+				continue
+			}
+			temp := newIdentifierIssuer("_:b")
+			temp.issue(id)
+			hash, issuer := c.hashNDegreeQuads(id, temp)
+			paths = append(paths, hashPath{hash: hash, issuer: issuer})
+		}
+		sort.SliceStable(paths, func(i, j int) bool {
+			return paths[i].hash < paths[j].hash
+		})
+		for _, p := range paths {
+			for _, existing := range p.issuer.order {
+				c.canonical.issue(existing)
+			}
+		}
+	}
+	lines := make([]string, len(dataset))
+	for i, q := range dataset {
+		lines[i] = relabel(q, func(id string) string {
+			return c.canonical.issue(id)
+		}).nquad()
+	}
+	sort.Strings(lines)
+	return strings.Join(dedupe(lines), "")
+}
+
+// hashFirstDegreeQuads hashes the quads mentioning the blank node, with it
+// relabeled _:a and every other blank node _:z.
+func (c *canonicalizer) hashFirstDegreeQuads(id string) string {
+	if h, ok := c.firstDegree[id]; ok {
+		return h
+	}
+	qs := c.quads[id]
+	lines := make([]string, len(qs))
+	for i, q := range qs {
+		lines[i] = relabel(q, func(other string) string {
+			if other == id {
+				return "_:a"
+			}
+			return "_:z"
+		}).nquad()
+	}
+	sort.Strings(lines)
+	h := hash(strings.Join(lines, ""))
+	c.firstDegree[id] = h
+	return h
+}
+
+// hashRelatedBlankNode hashes a blank node related to another by the quad, in
+// the position given by s, o, or g.
+func (c *canonicalizer) hashRelatedBlankNode(related string, q quad, issuer *identifierIssuer, position string) string {
+	var id string
+	if c.canonical.has(related) {
+		id = c.canonical.issue(related)
+	} else if issuer.has(related) {
+		id = issuer.issue(related)
+	} else {
+		id = c.hashFirstDegreeQuads(related)
+	}
+	input := position
+	if position != "g" {
+		input += q.predicate.nquad()
+	}
+	return hash(input + id)
+}
+
+// hashNDegreeQuads hashes the blank node by the paths to the blank nodes
+// related to it, choosing the lexicographically least path for each group of
+// related blank nodes. It returns the issuer of the identifiers on the chosen
+// paths.
+func (c *canonicalizer) hashNDegreeQuads(id string, issuer *identifierIssuer) (string, *identifierIssuer) {
+	hashToRelated := make(map[string][]string)
+	for _, q := range c.quads[id] {
+		for _, related := range []struct {
+			term     rdfTerm
+			position string
+		}{
+			{q.subject, "s"},
+			{q.object, "o"},
+			{q.graph, "g"},
+		} {
+			if related.term.kind != blankTerm || related.term.value == id {
+				continue
+			}
+			h := c.hashRelatedBlankNode(related.term.value, q, issuer, related.position)
+			hashToRelated[h] = append(hashToRelated[h], related.term.value)
+		}
+	}
+	var data strings.Builder
+	for _, h := range sortedHashes(hashToRelated) {
+		data.WriteString(h)
+		var chosenPath string
+		var chosenIssuer *identifierIssuer
+		permute(hashToRelated[h], func(permutation []string) {
+			issuerCopy := issuer.clone()
+			path := ""
+			var recursion []string
+			for _, related := range permutation {
+				if c.canonical.has(related) {
+					path += c.canonical.issue(related)
+				} else {
+					if !issuerCopy.has(related) {
+						recursion = append(recursion, related)
+					}
+					path += issuerCopy.issue(related)
+				}
+				if isWorsePath(path, chosenPath) {
+					return
+				}
+			}
+			for _, related := range recursion {
+				result, resultIssuer := c.hashNDegreeQuads(related, issuerCopy)
+				path += issuerCopy.issue(related) + "<" + result + ">"
+				issuerCopy = resultIssuer
+				if isWorsePath(path, chosenPath) {
+					return
+				}
+			}
+			if len(chosenPath) == 0 || path < chosenPath {
+				chosenPath = path
+				chosenIssuer = issuerCopy
+			}
+		})
+		data.WriteString(chosenPath)
+		issuer = chosenIssuer
+	}
+	return hash(data.String()), issuer
+}
+
+// isWorsePath determines whether the path, even if incomplete, cannot be
+// chosen over the path chosen so far.
+func isWorsePath(path, chosen string) bool {
+	return len(chosen) > 0 && len(path) >= len(chosen) && path > chosen
+}
+
+// permute calls fn with every permutation of the identifiers.
+func permute(ids []string, fn func([]string)) {
+	p := append([]string(nil), ids...)
+	var generate func(k int)
+	generate = func(k int) {
+		if k <= 1 {
+			fn(p)
+			return
+		}
+		for i := 0; i < k; i++ {
+			generate(k - 1)
+			if k%2 == 0 {
+				p[i], p[k-1] = p[k-1], p[i]
+			} else {
+				p[0], p[k-1] = p[k-1], p[0]
+			}
+		}
+	}
+	generate(len(p))
+}
+
+// relabel returns the quad with each blank node identifier replaced.
+func relabel(q quad, label func(string) string) quad {
+	for _, t := range []*rdfTerm{&q.subject, &q.object, &q.graph} {
+		if t.kind == blankTerm {
+			t.value = label(t.value)
+		}
+	}
+	return q
+}
+
+// sameQuad determines whether two quads are the same statement.
+func sameQuad(a, b quad) bool {
+	return a == b
+}
+
+// hash returns the hex encoded SHA-256 hash of the string.
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// sortedHashes returns the keys of the map in code point order.
+func sortedHashes(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedSet returns the members of the set in code point order.
+func sortedSet(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// dedupe removes adjacent duplicates from the sorted lines, since a dataset
+// is a set of quads.
+func dedupe(lines []string) []string {
+	out := lines[:0]
+	for i, l := range lines {
+		if i == 0 || l != lines[i-1] {
+			out = append(out, l)
+		}
+	}
+	return out
+}
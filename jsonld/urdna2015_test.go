@@ -0,0 +1,131 @@
+package jsonld
+
+import (
+	"strings"
+	"testing"
+)
+
+// parseNQuads parses N-Quads whose terms are IRIs or blank nodes, in the
+// default graph.
+func parseNQuads(t *testing.T, s string) []quad {
+	var dataset []quad
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		f := strings.Fields(line)
+		if len(f) != 4 || f[3] != "." {
+			t.Fatalf("unsupported n-quad: %s", line)
+		}
+		term := func(s string) rdfTerm {
+			if strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">") {
+				return rdfTerm{kind: iriTerm, value: s[1 : len(s)-1]}
+			}
+			return rdfTerm{kind: blankTerm, value: s}
+		}
+		dataset = append(dataset, quad{
+			subject:   term(f[0]),
+			predicate: term(f[1]),
+			object:    term(f[2]),
+			graph:     rdfTerm{kind: defaultGraphTerm},
+		})
+	}
+	return dataset
+}
+
+// The examples of the RDF Dataset Canonicalization specification, with the
+// first degree hashes it gives for their blank nodes.
+func TestNormalizeSpecificationExamples(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		firstDegree map[string]string
+		expected    string
+	}{
+		{
+			name: "unique hashes",
+			input: `
+<http://example.com/#p> <http://example.com/#q> _:e0 .
+<http://example.com/#p> <http://example.com/#r> _:e1 .
+_:e0 <http://example.com/#s> <http://example.com/#u> .
+_:e1 <http://example.com/#t> <http://example.com/#u> .
+`,
+			firstDegree: map[string]string{
+				"_:e0": "21d1dd5ba21f3dee9d76c0c00c260fa6f5d5d65315099e553026f4828d0dc77a",
+				"_:e1": "6fa0b9bdb376852b5743ff39ca4cbf7ea14d34966b2828478fbf222e7c764473",
+			},
+			expected: `<http://example.com/#p> <http://example.com/#q> _:c14n0 .
+<http://example.com/#p> <http://example.com/#r> _:c14n1 .
+_:c14n0 <http://example.com/#s> <http://example.com/#u> .
+_:c14n1 <http://example.com/#t> <http://example.com/#u> .
+`,
+		},
+		{
+			name: "shared hashes",
+			input: `
+<http://example.com/#p> <http://example.com/#q> _:e0 .
+<http://example.com/#p> <http://example.com/#q> _:e1 .
+_:e0 <http://example.com/#p> _:e2 .
+_:e1 <http://example.com/#p> _:e3 .
+_:e2 <http://example.com/#r> _:e3 .
+`,
+			firstDegree: map[string]string{
+				"_:e0": "3b26142829b8887d011d779079a243bd61ab53c3990d550320a17b59ade6ba36",
+				"_:e1": "3b26142829b8887d011d779079a243bd61ab53c3990d550320a17b59ade6ba36",
+				"_:e2": "15973d39de079913dac841ac4fa8c4781c0febfba5e83e5c6e250869587f8659",
+				"_:e3": "7e790a99273eed1dc57e43205d37ce232252c85b26ca4a6ff74ff3b5aea7bccd",
+			},
+			expected: `<http://example.com/#p> <http://example.com/#q> _:c14n2 .
+<http://example.com/#p> <http://example.com/#q> _:c14n3 .
+_:c14n0 <http://example.com/#r> _:c14n1 .
+_:c14n2 <http://example.com/#p> _:c14n1 .
+_:c14n3 <http://example.com/#p> _:c14n0 .
+`,
+		},
+	}
+	for _, test := range tests {
+		dataset := parseNQuads(t, test.input)
+		c := &canonicalizer{
+			quads:       make(map[string][]quad),
+			canonical:   newIdentifierIssuer("_:c14n"),
+			firstDegree: make(map[string]string),
+		}
+		for _, q := range dataset {
+			for _, term := range []rdfTerm{q.subject, q.object} {
+				if term.kind == blankTerm {
+					c.quads[term.value] = append(c.quads[term.value], q)
+				}
+			}
+		}
+		for id, expected := range test.firstDegree {
+			if actual := c.hashFirstDegreeQuads(id); actual != expected {
+				t.Fatalf("(%q): expected first degree hash of %s %s, got %s", test.name, id, expected, actual)
+			}
+		}
+		if actual := normalize(dataset); actual != test.expected {
+			t.Fatalf("(%q): expected\n%s\ngot\n%s", test.name, test.expected, actual)
+		}
+	}
+}
+
+// Blank node graphs whose canonical form follows from their symmetry alone.
+func TestNormalizeSymmetricGraphs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "circle of two",
+			input: `
+_:a <http://example.com/#p> _:b .
+_:b <http://example.com/#p> _:a .
+`,
+			expected: `_:c14n0 <http://example.com/#p> _:c14n1 .
+_:c14n1 <http://example.com/#p> _:c14n0 .
+`,
+		},
+	}
+	for _, test := range tests {
+		if actual := normalize(parseNQuads(t, test.input)); actual != test.expected {
+			t.Fatalf("(%q): expected\n%s\ngot\n%s", test.name, test.expected, actual)
+		}
+	}
+}
@@ -36,6 +36,22 @@ type Actor interface {
 	//
 	// If the Federated Protocol is enabled, side effects will occur.
 	PostInbox(c context.Context, w http.ResponseWriter, r *http.Request) (bool, error)
+	// PostSharedInbox returns true if the request was handled as an
+	// ActivityPub POST to the shared inbox of this server. If false, the
+	// request was not an ActivityPub request and may still be handled by
+	// the caller in another way.
+	//
+	// If the error is nil, then the ResponseWriter's headers and response
+	// has already been written. If a non-nil error is returned, then no
+	// response has been written.
+	//
+	// The activity is posted to the inbox of each local actor it is for,
+	// as if it had been delivered to each of them individually. If the
+	// Federated Protocol is not enabled, or the Actor was created by
+	// NewCustomActor with a DelegateActor that is not a
+	// SharedInboxDelegate, writes the http.StatusMethodNotAllowed status
+	// code in the response.
+	PostSharedInbox(c context.Context, w http.ResponseWriter, r *http.Request) (bool, error)
 	// GetInbox returns true if the request was handled as an ActivityPub
 	// GET to an actor's inbox. If false, the request was not an ActivityPub
 	// request and may still be handled by the caller in another way, such
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/go-fed/activity/streams/vocab"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"time"
 )
 
 // TODO: Rename GetType and GetName
@@ -34,6 +37,19 @@ type baseActor struct {
 	enableFederatedProtocol bool
 	// clock simply tracks the current time.
 	clock Clock
+	// requireDigest rejects POSTs to the inbox whose Digest header is
+	// missing or does not match the body.
+	requireDigest bool
+	// fetchVerifier, if set, authenticates GETs to the inbox and outbox
+	// by their HTTP Signature.
+	fetchVerifier *HttpSigAuthenticator
+	// replayStore, if set, rejects POSTs to the inbox that are outside of
+	// replaySkew of the current time or that were already accepted.
+	replayStore ReplayStore
+	replaySkew  time.Duration
+	// inboxQueue, if set, processes activities POSTed to inboxes after the
+	// request has been answered.
+	inboxQueue *InboxQueue
 }
 
 // NewSocialActor builds a new Actor concept that handles only the Social
@@ -46,13 +62,16 @@ type baseActor struct {
 // compliant with the ActivityPub specification, while providing enough freedom
 // to be productive without shooting one's self in the foot.
 //
+// An error is returned if the options cannot be applied.
+//
 // Do not try to use NewSocialActor and NewFederatingActor together to cover
 // both the Social and Federating parts of the protocol. Instead, use NewActor.
 func NewSocialActor(c CommonBehavior,
 	c2s SocialProtocol,
 	db Database,
-	clock Clock) Actor {
-	return &baseActor{
+	clock Clock,
+	opts ...ActorOption) (Actor, error) {
+	return newBaseActor(&baseActor{
 		delegate: &sideEffectActor{
 			common: c,
 			c2s:    c2s,
@@ -61,7 +80,7 @@ func NewSocialActor(c CommonBehavior,
 		},
 		enableSocialProtocol: true,
 		clock:                clock,
-	}
+	}, opts)
 }
 
 // NewFederatingActor builds a new Actor concept that handles only the Federating
@@ -74,13 +93,16 @@ func NewSocialActor(c CommonBehavior,
 // compliant with the ActivityPub specification, while providing enough freedom
 // to be productive without shooting one's self in the foot.
 //
+// An error is returned if the options cannot be applied.
+//
 // Do not try to use NewSocialActor and NewFederatingActor together to cover
 // both the Social and Federating parts of the protocol. Instead, use NewActor.
 func NewFederatingActor(c CommonBehavior,
 	s2s FederatingProtocol,
 	db Database,
-	clock Clock) Actor {
-	return &baseActor{
+	clock Clock,
+	opts ...ActorOption) (Actor, error) {
+	return newBaseActor(&baseActor{
 		delegate: &sideEffectActor{
 			common: c,
 			s2s:    s2s,
@@ -89,7 +111,7 @@ func NewFederatingActor(c CommonBehavior,
 		},
 		enableFederatedProtocol: true,
 		clock:                   clock,
-	}
+	}, opts)
 }
 
 // NewActor builds a new Actor concept that handles both the Social and
@@ -101,12 +123,15 @@ func NewFederatingActor(c CommonBehavior,
 // It leverages as much of go-fed as possible to ensure the implementation is
 // compliant with the ActivityPub specification, while providing enough freedom
 // to be productive without shooting one's self in the foot.
+//
+// An error is returned if the options cannot be applied.
 func NewActor(c CommonBehavior,
 	c2s SocialProtocol,
 	s2s FederatingProtocol,
 	db Database,
-	clock Clock) Actor {
-	return &baseActor{
+	clock Clock,
+	opts ...ActorOption) (Actor, error) {
+	return newBaseActor(&baseActor{
 		delegate: &sideEffectActor{
 			common: c,
 			c2s:    c2s,
@@ -117,7 +142,7 @@ func NewActor(c CommonBehavior,
 		enableSocialProtocol:    true,
 		enableFederatedProtocol: true,
 		clock:                   clock,
-	}
+	}, opts)
 }
 
 // NewCustomActor allows clients to create a custom ActivityPub implementation
@@ -129,15 +154,32 @@ func NewActor(c CommonBehavior,
 //
 // It is possible to create a DelegateActor that is not ActivityPub compliant.
 // Use with care.
+//
+// An error is returned if the options cannot be applied.
 func NewCustomActor(delegate DelegateActor,
 	enableSocialProtocol, enableFederatedProtocol bool,
-	clock Clock) Actor {
-	return &baseActor{
+	clock Clock,
+	opts ...ActorOption) (Actor, error) {
+	return newBaseActor(&baseActor{
 		delegate:                delegate,
 		enableSocialProtocol:    enableSocialProtocol,
 		enableFederatedProtocol: enableFederatedProtocol,
 		clock:                   clock,
+	}, opts)
+}
+
+// newBaseActor applies the options to the baseActor, then starts its
+// InboxQueue, if any.
+func newBaseActor(b *baseActor, opts []ActorOption) (Actor, error) {
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.inboxQueue != nil {
+		if err := b.inboxQueue.start(b.delegate); err != nil {
+			return nil, err
+		}
 	}
+	return b, nil
 }
 
 // PostInbox implements the generic algorithm for handling a POST request to an
@@ -154,6 +196,44 @@ func (b *baseActor) PostInbox(c context.Context, w http.ResponseWriter, r *http.
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return true, nil
 	}
+	return b.postInbox(c, w, r, func(activity Activity) ([]*url.URL, error) {
+		return []*url.URL{r.URL}, nil
+	})
+}
+
+// PostSharedInbox implements the generic algorithm for handling a POST request
+// to the shared inbox of a server, independent on an application. It relies on
+// a delegate implementing SharedInboxDelegate to determine the inboxes of the
+// local actors the activity is for.
+func (b *baseActor) PostSharedInbox(c context.Context, w http.ResponseWriter, r *http.Request) (bool, error) {
+	// Do nothing if it is not an ActivityPub POST request.
+	if !isActivityPubPost(r) {
+		return false, nil
+	}
+	// If the Federated Protocol is not enabled, or the delegate has no
+	// notion of a shared inbox, then this endpoint is not enabled.
+	shared, ok := b.delegate.(SharedInboxDelegate)
+	if !b.enableFederatedProtocol || !ok {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return true, nil
+	}
+	return b.postInbox(c, w, r, func(activity Activity) ([]*url.URL, error) {
+		return shared.SharedInboxRecipients(c, activity)
+	})
+}
+
+// postInbox authenticates and authorizes the activity POSTed in the request,
+// then posts it to each of the inboxes determined by inboxesFn.
+func (b *baseActor) postInbox(c context.Context, w http.ResponseWriter, r *http.Request, inboxesFn func(activity Activity) ([]*url.URL, error)) (bool, error) {
+	// If required, reject requests that are stale or from the future.
+	var date time.Time
+	if b.replayStore != nil {
+		var err error
+		if date, err = checkDate(r, b.clock, b.replaySkew); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return true, nil
+		}
+	}
 	// Check the peer request is authentic.
 	shouldReturn, err := b.delegate.AuthenticatePostInbox(c, w, r)
 	if err != nil {
@@ -161,26 +241,41 @@ func (b *baseActor) PostInbox(c context.Context, w http.ResponseWriter, r *http.
 	} else if shouldReturn {
 		return true, nil
 	}
+	// The origin of the objects embedded in the activity is checked
+	// against the actor verified as its sender.
+	if requester := RequesterFromContext(r.Context()); requester != nil {
+		c = WithRequester(c, requester)
+	}
 	// Begin processing the request, but have not yet applied
-	// authorization (ex: blocks). Obtain the activity reject unknown
-	// activities.
+	// authorization (ex: blocks). Read the body, which is checked before
+	// it is converted into an activity.
 	raw, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return true, err
 	}
-	var m map[string]interface{}
-	if err = json.Unmarshal(raw, &m); err != nil {
-		return true, err
+	// If required, ensure the body is the one that was digested.
+	if b.requireDigest {
+		if err = verifyDigest(r.Header, raw); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return true, nil
+		}
 	}
-	// TODO: No longer reject unknown activities.
-	asValue, err := toType(c, m)
+	// If required, reject a replay of an already accepted request.
+	if b.replayStore != nil {
+		seen, err := b.replayStore.Seen(c, replayKey(r, date, raw), date.Add(b.replaySkew))
+		if err != nil {
+			return true, err
+		} else if seen {
+			w.WriteHeader(http.StatusUnauthorized)
+			return true, nil
+		}
+	}
+	// Activities of types unknown to go-fed are converted into an
+	// UnknownActivity.
+	activity, err := toActivity(c, raw)
 	if err != nil {
 		return true, err
 	}
-	activity, ok := asValue.(Activity)
-	if !ok {
-		return true, fmt.Errorf("activity streams value is not an Activity: %T", asValue)
-	}
 	if activity.GetActivityStreamsId() == nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return true, nil
@@ -192,25 +287,47 @@ func (b *baseActor) PostInbox(c context.Context, w http.ResponseWriter, r *http.
 	} else if shouldReturn {
 		return true, nil
 	}
-	// Post the activity to the actor's inbox and trigger side effects for
-	// that particular Activity type. It is up to the delegate to resolve
-	// the given map.
-	err = b.delegate.PostInbox(c, r.URL, activity)
+	inboxes, err := inboxesFn(activity)
 	if err != nil {
-		// Special case: We know it is a bad request if the object or
-		// target properties needed to be populated, but weren't.
-		//
-		// Send the rejection to the peer.
-		if err == ErrObjectRequired || err == ErrTargetRequired {
-			w.WriteHeader(http.StatusBadRequest)
+		return true, err
+	}
+	// If asynchronous, the side effects are applied once the peer has been
+	// told the activity was accepted.
+	if b.inboxQueue != nil {
+		if err = b.inboxQueue.accept(c, raw, inboxes, activity); err == ErrInboxQueueFull {
+			w.WriteHeader(http.StatusServiceUnavailable)
 			return true, nil
+		} else if err != nil {
+			return true, err
 		}
-		return true, err
+		w.WriteHeader(http.StatusAccepted)
+		return true, nil
 	}
-	// Our side effects are complete, now delegate determining whether to
-	// do inbox forwarding, as well as the action to do it.
-	if err := b.delegate.InboxForwarding(c, r.URL, activity); err != nil {
-		return true, err
+	for _, inboxIRI := range inboxes {
+		// Post the activity to the actor's inbox and trigger side
+		// effects for that particular Activity type. It is up to the
+		// delegate to resolve the given map.
+		c := withOriginCheck(c)
+		err = b.delegate.PostInbox(c, inboxIRI, activity)
+		if err != nil {
+			// Special case: We know it is a bad request if the
+			// object or target properties needed to be populated,
+			// but weren't, or if an object was rejected for not
+			// being on the host of the peer.
+			//
+			// Send the rejection to the peer.
+			if _, ok := err.(*OriginMismatchError); ok || err == ErrObjectRequired || err == ErrTargetRequired || err == ErrNoRequester {
+				w.WriteHeader(http.StatusBadRequest)
+				return true, nil
+			}
+			return true, err
+		}
+		// Our side effects are complete, now delegate determining
+		// whether to do inbox forwarding, as well as the action to do
+		// it.
+		if err := b.delegate.InboxForwarding(c, inboxIRI, activity); err != nil {
+			return true, err
+		}
 	}
 	// Request has been processed. Begin responding to the request.
 	//
@@ -227,14 +344,41 @@ func (b *baseActor) GetInbox(c context.Context, w http.ResponseWriter, r *http.R
 	if !isActivityPubGet(r) {
 		return false, nil
 	}
+	// If required, identify the requester by their HTTP Signature.
+	c, shouldReturn, err := b.authorizeFetch(c, w, r)
+	if err != nil {
+		return true, err
+	} else if shouldReturn {
+		return true, nil
+	}
 	// Delegate authenticating and authorizing the request.
-	shouldReturn, err := b.delegate.AuthenticateGetInbox(c, w, r)
+	shouldReturn, err = b.delegate.AuthenticateGetInbox(c, w, r)
 	if err != nil {
 		return true, err
 	} else if shouldReturn {
 		return true, nil
 	}
 	// Everything is good to begin processing the request.
+	//
+	// If the delegate pages the inbox, serve the collection or the page
+	// requested.
+	if p, ok := b.delegate.(PagedBoxDelegate); ok {
+		t, paged, err := p.GetPagedInbox(c, r)
+		if err == ErrInvalidCursor {
+			w.WriteHeader(http.StatusBadRequest)
+			return true, nil
+		} else if err != nil {
+			return true, err
+		} else if paged {
+			// Deduplicate the 'orderedItems' property of a page by ID.
+			if oc, ok := t.(orderedItemser); ok {
+				if err = dedupeOrderedItems(oc); err != nil {
+					return true, err
+				}
+			}
+			return true, b.writeCollection(w, t)
+		}
+	}
 	oc, err := b.delegate.GetInbox(c, r)
 	if err != nil {
 		return true, err
@@ -245,26 +389,7 @@ func (b *baseActor) GetInbox(c context.Context, w http.ResponseWriter, r *http.R
 		return true, err
 	}
 	// Request has been processed. Begin responding to the request.
-	//
-	// Serialize the OrderedCollection.
-	m, err := serialize(oc)
-	if err != nil {
-		return true, err
-	}
-	raw, err := json.Marshal(m)
-	if err != nil {
-		return true, err
-	}
-	// Write the response.
-	addResponseHeaders(w.Header(), b.clock, raw)
-	w.WriteHeader(http.StatusOK)
-	n, err := w.Write(raw)
-	if err != nil {
-		return true, err
-	} else if n != len(raw) {
-		return true, fmt.Errorf("ResponseWriter.Write wrote %d of %d bytes", n, len(raw))
-	}
-	return true, nil
+	return true, b.writeCollection(w, oc)
 }
 
 // PostOutbox implements the generic algorithm for handling a POST request to an
@@ -296,12 +421,11 @@ func (b *baseActor) PostOutbox(c context.Context, w http.ResponseWriter, r *http
 	if err = json.Unmarshal(raw, &m); err != nil {
 		return true, err
 	}
-	// Note that converting to a Type will NOT successfully convert types
-	// not known to go-fed. This prevents accidentally wrapping an Activity
-	// type unknown to go-fed in a Create below. Instead,
-	// streams.ErrUnhandledType will be returned here.
-	//
-	// TODO: No longer reject unknown activities.
+	// Note that converting to a Type will only convert types not known to
+	// go-fed if they have an 'actor', into an UnknownActivity. This
+	// prevents accidentally wrapping an Activity type unknown to go-fed in
+	// a Create below. Other unknown types return streams.ErrUnhandledType
+	// here.
 	asValue, err := toType(c, m)
 	if err != nil {
 		return true, err
@@ -365,37 +489,86 @@ func (b *baseActor) GetOutbox(c context.Context, w http.ResponseWriter, r *http.
 	if !isActivityPubGet(r) {
 		return false, nil
 	}
+	// If required, identify the requester by their HTTP Signature.
+	c, shouldReturn, err := b.authorizeFetch(c, w, r)
+	if err != nil {
+		return true, err
+	} else if shouldReturn {
+		return true, nil
+	}
 	// Delegate authenticating and authorizing the request.
-	shouldReturn, err := b.delegate.AuthenticateGetOutbox(c, w, r)
+	shouldReturn, err = b.delegate.AuthenticateGetOutbox(c, w, r)
 	if err != nil {
 		return true, err
 	} else if shouldReturn {
 		return true, nil
 	}
 	// Everything is good to begin processing the request.
+	//
+	// If the delegate pages the outbox, serve the collection or the page
+	// requested.
+	if p, ok := b.delegate.(PagedBoxDelegate); ok {
+		t, paged, err := p.GetPagedOutbox(c, r)
+		if err == ErrInvalidCursor {
+			w.WriteHeader(http.StatusBadRequest)
+			return true, nil
+		} else if err != nil {
+			return true, err
+		} else if paged {
+			// Deduplicate the 'orderedItems' property of a page by ID.
+			if oc, ok := t.(orderedItemser); ok {
+				if err = dedupeOrderedItems(oc); err != nil {
+					return true, err
+				}
+			}
+			return true, b.writeCollection(w, t)
+		}
+	}
 	oc, err := b.delegate.GetOutbox(c, r)
 	if err != nil {
 		return true, err
 	}
 	// Request has been processed. Begin responding to the request.
-	//
-	// Serialize the OrderedCollection.
-	m, err := serialize(oc)
+	return true, b.writeCollection(w, oc)
+}
+
+// writeCollection serializes the collection or collection page in an OK
+// response.
+func (b *baseActor) writeCollection(w http.ResponseWriter, t vocab.Type) error {
+	m, err := serialize(t)
 	if err != nil {
-		return true, err
+		return err
 	}
 	raw, err := json.Marshal(m)
 	if err != nil {
-		return true, err
+		return err
 	}
-	// Write the response.
 	addResponseHeaders(w.Header(), b.clock, raw)
 	w.WriteHeader(http.StatusOK)
 	n, err := w.Write(raw)
 	if err != nil {
-		return true, err
+		return err
 	} else if n != len(raw) {
-		return true, fmt.Errorf("ResponseWriter.Write wrote %d of %d bytes", n, len(raw))
+		return fmt.Errorf("ResponseWriter.Write wrote %d of %d bytes", n, len(raw))
 	}
-	return true, nil
+	return nil
+}
+
+// authorizeFetch verifies the HTTP Signature on a GET request if authorized
+// fetch is required, returning a context carrying the verified requester.
+//
+// If the request cannot be authenticated by its signature, an Unauthorized
+// status is written and shouldReturn is true.
+func (b *baseActor) authorizeFetch(c context.Context, w http.ResponseWriter, r *http.Request) (context.Context, bool, error) {
+	if b.fetchVerifier == nil {
+		return c, false, nil
+	}
+	requester, err := b.fetchVerifier.VerifyRequest(c, r)
+	if status := authenticationFailureStatus(err); status != 0 {
+		w.WriteHeader(status)
+		return c, true, nil
+	} else if err != nil {
+		return c, true, err
+	}
+	return WithRequester(c, requester), false, nil
 }
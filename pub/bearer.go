@@ -0,0 +1,240 @@
+package pub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The HTTP header carrying a bearer token.
+	authorizationHeader = "Authorization"
+	// The HTTP header describing why a bearer token was refused.
+	wwwAuthenticateHeader = "WWW-Authenticate"
+	// The scheme prefix of a bearer token in the Authorization header.
+	bearerPrefix = "Bearer "
+)
+
+// Scope is a permission granted to a bearer token.
+type Scope string
+
+const (
+	// ScopeRead permits reading an actor's inbox and outbox.
+	ScopeRead Scope = "read"
+	// ScopeWrite permits posting activities to an actor's outbox.
+	ScopeWrite Scope = "write"
+	// ScopeFollow permits managing an actor's relationships, such as
+	// following, blocking, and accepting follow requests.
+	ScopeFollow Scope = "follow"
+)
+
+// followScopeTypes are the activities that require the follow scope to be
+// posted to an outbox.
+var followScopeTypes = map[string]bool{
+	"Follow": true,
+	"Block":  true,
+	"Accept": true,
+	"Reject": true,
+}
+
+// TokenInfo is what a bearer token grants: the actor it acts on behalf of, the
+// scopes it is permitted, and when it expires.
+//
+// A zero Expires never expires.
+type TokenInfo struct {
+	ActorIRI *url.URL
+	Scopes   []Scope
+	Expires  time.Time
+}
+
+// HasScope returns true if the token is permitted the scope.
+func (t TokenInfo) HasScope(s Scope) bool {
+	for _, scope := range t.Scopes {
+		if scope == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenStore looks up the bearer tokens issued by an application's OAuth2
+// authorization server.
+type TokenStore interface {
+	// Lookup returns what the token grants, or nil if the token is not
+	// known or was revoked.
+	Lookup(c context.Context, token string) (*TokenInfo, error)
+}
+
+// BearerAuthenticator authenticates requests from C2S clients by the OAuth2
+// bearer token in their Authorization header.
+//
+// Its methods satisfy the SocialProtocol and CommonBehavior methods of the
+// same names, so an application may delegate to them directly. When a request
+// is not permitted, the response is written as described by RFC 6750 and
+// shouldReturn is true.
+type BearerAuthenticator struct {
+	store TokenStore
+	db    Database
+	clock Clock
+}
+
+// NewBearerAuthenticator returns a new BearerAuthenticator.
+//
+// The Database determines which actor owns the inbox or outbox being
+// requested.
+func NewBearerAuthenticator(store TokenStore, db Database, clock Clock) *BearerAuthenticator {
+	return &BearerAuthenticator{
+		store: store,
+		db:    db,
+		clock: clock,
+	}
+}
+
+// AuthenticatePostOutbox requires a token with the write scope, for the actor
+// owning the outbox. If the body is an activity, every actor on it must be the
+// token's actor, and activities managing relationships also require the
+// follow scope.
+//
+// The request body is restored after being read, so it is safe to continue
+// processing the request afterwards.
+func (b *BearerAuthenticator) AuthenticatePostOutbox(c context.Context, w http.ResponseWriter, r *http.Request) (shouldReturn bool, err error) {
+	info, shouldReturn, err := b.authenticate(c, w, r, ScopeWrite)
+	if err != nil || shouldReturn {
+		return
+	}
+	if shouldReturn, err = b.mustOwnBox(c, w, info, r.URL, b.db.ActorForOutbox); err != nil || shouldReturn {
+		return
+	}
+	raw, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(raw))
+	var m map[string]interface{}
+	if err = json.Unmarshal(raw, &m); err != nil {
+		return
+	}
+	t, err := toType(c, m)
+	if err != nil {
+		return
+	}
+	if activity, ok := t.(Activity); ok {
+		if mustHaveKeyOwnerMatchActors(info.ActorIRI, activity) != nil {
+			writeBearerError(w, http.StatusForbidden, "insufficient_scope", "activity actor is not the token's actor")
+			shouldReturn = true
+		} else if followScopeTypes[activity.GetName()] && !info.HasScope(ScopeFollow) {
+			writeBearerError(w, http.StatusForbidden, "insufficient_scope", fmt.Sprintf("%s requires the %s scope", activity.GetName(), ScopeFollow))
+			shouldReturn = true
+		}
+	}
+	return
+}
+
+// AuthenticateGetOutbox permits requests without a token, leaving the
+// application to serve only public activities. Requests with a token require
+// it to be valid and have the read scope.
+func (b *BearerAuthenticator) AuthenticateGetOutbox(c context.Context, w http.ResponseWriter, r *http.Request) (shouldReturn bool, err error) {
+	if len(r.Header.Get(authorizationHeader)) == 0 {
+		return
+	}
+	_, shouldReturn, err = b.authenticate(c, w, r, ScopeRead)
+	return
+}
+
+// AuthenticateGetInbox requires a token with the read scope, for the actor
+// owning the inbox.
+func (b *BearerAuthenticator) AuthenticateGetInbox(c context.Context, w http.ResponseWriter, r *http.Request) (shouldReturn bool, err error) {
+	info, shouldReturn, err := b.authenticate(c, w, r, ScopeRead)
+	if err != nil || shouldReturn {
+		return
+	}
+	return b.mustOwnBox(c, w, info, r.URL, b.db.ActorForInbox)
+}
+
+// authenticate looks up the request's bearer token, and ensures it has not
+// expired and is permitted the scope.
+func (b *BearerAuthenticator) authenticate(c context.Context, w http.ResponseWriter, r *http.Request, scope Scope) (info *TokenInfo, shouldReturn bool, err error) {
+	auth := r.Header.Get(authorizationHeader)
+	if !strings.HasPrefix(auth, bearerPrefix) {
+		w.Header().Set(wwwAuthenticateHeader, "Bearer")
+		w.WriteHeader(http.StatusUnauthorized)
+		shouldReturn = true
+		return
+	}
+	info, err = b.store.Lookup(c, strings.TrimSpace(strings.TrimPrefix(auth, bearerPrefix)))
+	if err != nil {
+		return
+	}
+	if info == nil || (!info.Expires.IsZero() && !b.clock.Now().Before(info.Expires)) {
+		writeBearerError(w, http.StatusUnauthorized, "invalid_token", "token is unknown, revoked, or expired")
+		shouldReturn = true
+	} else if !info.HasScope(scope) {
+		writeBearerError(w, http.StatusForbidden, "insufficient_scope", fmt.Sprintf("token requires the %s scope", scope))
+		shouldReturn = true
+	}
+	return
+}
+
+// mustOwnBox ensures the token's actor is the owner of the inbox or outbox.
+func (b *BearerAuthenticator) mustOwnBox(c context.Context, w http.ResponseWriter, info *TokenInfo, boxIRI *url.URL, ownerFn func(context.Context, *url.URL) (*url.URL, error)) (shouldReturn bool, err error) {
+	owner, err := ownerFn(c, boxIRI)
+	if err != nil {
+		return
+	}
+	if owner.String() != info.ActorIRI.String() {
+		writeBearerError(w, http.StatusForbidden, "insufficient_scope", "token's actor does not own this collection")
+		shouldReturn = true
+	}
+	return
+}
+
+// writeBearerError writes a response refusing a bearer token.
+func writeBearerError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set(wwwAuthenticateHeader, fmt.Sprintf("Bearer error=%q, error_description=%q", code, description))
+	w.WriteHeader(status)
+}
+
+// MemoryTokenStore is a TokenStore kept in memory, suitable for tests and
+// single process applications.
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]TokenInfo
+}
+
+// NewMemoryTokenStore returns an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{
+		tokens: make(map[string]TokenInfo),
+	}
+}
+
+// Add issues a token, replacing any existing grant for it.
+func (m *MemoryTokenStore) Add(token string, info TokenInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[token] = info
+}
+
+// Revoke removes a token.
+func (m *MemoryTokenStore) Revoke(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tokens, token)
+}
+
+// Lookup returns what the token grants, or nil if it is not known.
+func (m *MemoryTokenStore) Lookup(c context.Context, token string) (*TokenInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if info, ok := m.tokens[token]; ok {
+		return &info, nil
+	}
+	return nil, nil
+}
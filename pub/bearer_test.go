@@ -0,0 +1,165 @@
+package pub
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// boxOwnerDatabase owns every inbox and outbox by the same actor.
+type boxOwnerDatabase struct {
+	Database
+	owner *url.URL
+}
+
+func (b boxOwnerDatabase) ActorForOutbox(c context.Context, outboxIRI *url.URL) (*url.URL, error) {
+	return b.owner, nil
+}
+
+func (b boxOwnerDatabase) ActorForInbox(c context.Context, inboxIRI *url.URL) (*url.URL, error) {
+	return b.owner, nil
+}
+
+func TestBearerAuthenticatorAuthenticatePostOutbox(t *testing.T) {
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	owner, err := url.Parse(testKeyOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMemoryTokenStore()
+	store.Add("writer", TokenInfo{ActorIRI: owner, Scopes: []Scope{ScopeRead, ScopeWrite}})
+	store.Add("follower", TokenInfo{ActorIRI: owner, Scopes: []Scope{ScopeWrite, ScopeFollow}})
+	store.Add("reader", TokenInfo{ActorIRI: owner, Scopes: []Scope{ScopeRead}})
+	store.Add("expired", TokenInfo{ActorIRI: owner, Scopes: []Scope{ScopeWrite}, Expires: now.Add(-time.Second)})
+	a := NewBearerAuthenticator(store, boxOwnerDatabase{owner: owner}, fixedClock(now))
+	tests := []struct {
+		name           string
+		token          string
+		actType        string
+		actor          string
+		expectedStatus int
+	}{
+		{
+			name:           "permitted",
+			token:          "writer",
+			actType:        "Like",
+			actor:          testKeyOwner,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing token",
+			actType:        "Like",
+			actor:          testKeyOwner,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "unknown token",
+			token:          "bogus",
+			actType:        "Like",
+			actor:          testKeyOwner,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "expired token",
+			token:          "expired",
+			actType:        "Like",
+			actor:          testKeyOwner,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing write scope",
+			token:          "reader",
+			actType:        "Like",
+			actor:          testKeyOwner,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "different actor",
+			token:          "writer",
+			actType:        "Like",
+			actor:          "https://example.com/users/mallory",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "follow without follow scope",
+			token:          "writer",
+			actType:        "Follow",
+			actor:          testKeyOwner,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "follow with follow scope",
+			token:          "follower",
+			actType:        "Follow",
+			actor:          testKeyOwner,
+			expectedStatus: http.StatusOK,
+		},
+	}
+	for _, test := range tests {
+		body := []byte(`{"@context":"https://www.w3.org/ns/activitystreams","type":"` + test.actType + `","actor":"` + test.actor + `","object":"https://example.net/users/bob"}`)
+		r := httptest.NewRequest("POST", "https://example.com/users/alice/outbox", bytes.NewReader(body))
+		if len(test.token) > 0 {
+			r.Header.Set(authorizationHeader, bearerPrefix+test.token)
+		}
+		w := httptest.NewRecorder()
+		shouldReturn, err := a.AuthenticatePostOutbox(context.Background(), w, r)
+		if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if shouldReturn != (test.expectedStatus != http.StatusOK) {
+			t.Fatalf("(%q): expected shouldReturn %v, got %v", test.name, !shouldReturn, shouldReturn)
+		} else if w.Code != test.expectedStatus {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expectedStatus, w.Code)
+		}
+	}
+}
+
+func TestBearerAuthenticatorAuthenticateGetInbox(t *testing.T) {
+	owner, err := url.Parse(testKeyOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := url.Parse("https://example.com/users/mallory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewMemoryTokenStore()
+	store.Add("reader", TokenInfo{ActorIRI: owner, Scopes: []Scope{ScopeRead}})
+	store.Add("other", TokenInfo{ActorIRI: other, Scopes: []Scope{ScopeRead}})
+	a := NewBearerAuthenticator(store, boxOwnerDatabase{owner: owner}, fixedClock(time.Now()))
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{
+			name:           "owner",
+			token:          "reader",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "not the owner",
+			token:          "other",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "https://example.com/users/alice/inbox", nil)
+		if len(test.token) > 0 {
+			r.Header.Set(authorizationHeader, bearerPrefix+test.token)
+		}
+		w := httptest.NewRecorder()
+		if _, err := a.AuthenticateGetInbox(context.Background(), w, r); err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if w.Code != test.expectedStatus {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expectedStatus, w.Code)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package pub
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The HTTP headers used to cache and revalidate dereferenced IRIs.
+	cacheControlHeader    = "Cache-Control"
+	etagHeader            = "ETag"
+	expiresHeader         = "Expires"
+	lastModifiedHeader    = "Last-Modified"
+	ifNoneMatchHeader     = "If-None-Match"
+	ifModifiedSinceHeader = "If-Modified-Since"
+)
+
+// DereferenceResponse is the result of a conditional dereference.
+type DereferenceResponse struct {
+	// Body is the dereferenced ActivityStreams data. It is empty when
+	// NotModified is true.
+	Body []byte
+	// NotModified is true if the peer responded that the previously
+	// fetched data is still current.
+	NotModified bool
+	// Header holds the response headers.
+	Header http.Header
+}
+
+// ConditionalDereferencer is a Transport able to revalidate a previous
+// dereference instead of fetching the same data again.
+//
+// A CachingTransport wrapping a Transport that does not implement it always
+// fetches entries anew once they expire.
+type ConditionalDereferencer interface {
+	// DereferenceIfModified fetches the ActivityStreams object located at
+	// this IRI, unless it still matches the etag or has not been modified
+	// since lastModified. Either may be empty, and when both are, the
+	// request is not conditional.
+	DereferenceIfModified(c context.Context, iri *url.URL, etag, lastModified string) (*DereferenceResponse, error)
+}
+
+// Invalidator is a Transport that caches the data it dereferences, which is
+// invalidated when an Update or Delete of that data is received.
+type Invalidator interface {
+	// Invalidate removes any cached data for the IRI.
+	Invalidate(c context.Context, iri *url.URL) error
+}
+
+// CachedDereference is an entry in a DereferenceCache.
+type CachedDereference struct {
+	// Body is the dereferenced ActivityStreams data.
+	Body []byte
+	// StatusCode is zero for data that was successfully dereferenced, or
+	// the status of a peer's response that the IRI was not found or gone.
+	StatusCode int
+	// ETag and LastModified are the validators the peer responded with,
+	// used to revalidate the entry once it expires.
+	ETag         string
+	LastModified string
+	// Expires is when the entry must be revalidated or fetched again.
+	Expires time.Time
+}
+
+// DereferenceCache stores the results of dereferencing IRIs for a
+// CachingTransport.
+//
+// It must be safe for concurrent use, as it is shared by the Transports
+// created for every request.
+type DereferenceCache interface {
+	// Get returns the entry for the IRI, or nil if there is none.
+	Get(c context.Context, iri *url.URL) (*CachedDereference, error)
+	// Set stores the entry for the IRI.
+	Set(c context.Context, iri *url.URL, d *CachedDereference) error
+	// Delete removes any entry for the IRI.
+	Delete(c context.Context, iri *url.URL) error
+}
+
+// memoryDereferenceCache is a DereferenceCache holding a bounded number of
+// entries in memory, evicting the least recently used first.
+type memoryDereferenceCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	// lru holds *memoryCacheEntry, the most recently used at the front.
+	lru *list.List
+}
+
+// memoryCacheEntry is an element of a memoryDereferenceCache's lru list.
+type memoryCacheEntry struct {
+	key string
+	d   *CachedDereference
+}
+
+// NewMemoryDereferenceCache returns a DereferenceCache that holds up to
+// capacity entries in memory. When full, the least recently used entry is
+// forgotten to make room for a new one.
+func NewMemoryDereferenceCache(capacity int) DereferenceCache {
+	return &memoryDereferenceCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		lru:      list.New(),
+	}
+}
+
+// Get returns the entry for the IRI, marking it as recently used.
+func (m *memoryDereferenceCache) Get(c context.Context, iri *url.URL) (*CachedDereference, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[cacheKey(iri)]
+	if !ok {
+		return nil, nil
+	}
+	m.lru.MoveToFront(e)
+	return e.Value.(*memoryCacheEntry).d, nil
+}
+
+// Set stores the entry for the IRI, evicting the least recently used entries
+// as needed.
+func (m *memoryDereferenceCache) Set(c context.Context, iri *url.URL, d *CachedDereference) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := cacheKey(iri)
+	if e, ok := m.entries[key]; ok {
+		e.Value.(*memoryCacheEntry).d = d
+		m.lru.MoveToFront(e)
+		return nil
+	}
+	for m.lru.Len() > 0 && m.lru.Len() >= m.capacity {
+		oldest := m.lru.Back()
+		delete(m.entries, oldest.Value.(*memoryCacheEntry).key)
+		m.lru.Remove(oldest)
+	}
+	m.entries[key] = m.lru.PushFront(&memoryCacheEntry{key: key, d: d})
+	return nil
+}
+
+// Delete removes any entry for the IRI.
+func (m *memoryDereferenceCache) Delete(c context.Context, iri *url.URL) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := cacheKey(iri)
+	if e, ok := m.entries[key]; ok {
+		delete(m.entries, key)
+		m.lru.Remove(e)
+	}
+	return nil
+}
+
+// cacheKey identifies the document at an IRI, ignoring its fragment so that
+// an actor and its keys share an entry.
+func cacheKey(iri *url.URL) string {
+	u := *iri
+	u.Fragment = ""
+	return u.String()
+}
+
+// CachingTransport is a Transport that caches the data it dereferences, such
+// as remote actors and their public keys, with a DereferenceCache.
+//
+// Entries are cached for as long as the peer's Cache-Control or Expires
+// headers permit, or for a default time if it sent neither. Expired entries
+// are revalidated with their ETag or Last-Modified values when the wrapped
+// Transport is a ConditionalDereferencer. Responses that an IRI is not found
+// or gone are also cached, for a separate amount of time.
+//
+// Since the cache is shared across actors, a peer that serves different data
+// depending on which actor signed the request should mark its responses with
+// "Cache-Control: private" or "no-store", which are not cached.
+//
+// Deliver and BatchDeliver are not cached.
+type CachingTransport struct {
+	Transport
+	cache       DereferenceCache
+	clock       Clock
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// NewCachingTransport wraps the Transport. The cache should be shared by all
+// the CachingTransports an application creates.
+//
+// Data is cached for the ttl when the peer does not say how long to, and
+// responses that an IRI is not found or gone are cached for the negativeTTL.
+// If negativeTTL is zero or negative, then such responses are not cached.
+func NewCachingTransport(t Transport, cache DereferenceCache, clock Clock, ttl, negativeTTL time.Duration) *CachingTransport {
+	return &CachingTransport{
+		Transport:   t,
+		cache:       cache,
+		clock:       clock,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+// Dereference returns the cached data for the IRI, fetching or revalidating
+// it first if necessary.
+func (t *CachingTransport) Dereference(c context.Context, iri *url.URL) ([]byte, error) {
+	now := t.clock.Now()
+	d, err := t.cache.Get(c, iri)
+	if err != nil {
+		return nil, err
+	}
+	if d != nil && now.Before(d.Expires) {
+		return d.result(iri)
+	}
+	cd, conditional := t.Transport.(ConditionalDereferencer)
+	var resp *DereferenceResponse
+	if conditional && d != nil && d.StatusCode == 0 {
+		resp, err = cd.DereferenceIfModified(c, iri, d.ETag, d.LastModified)
+	} else if conditional {
+		resp, err = cd.DereferenceIfModified(c, iri, "", "")
+	} else {
+		var b []byte
+		if b, err = t.Transport.Dereference(c, iri); err == nil {
+			resp = &DereferenceResponse{Body: b}
+		}
+	}
+	if err != nil {
+		if se, ok := err.(*HttpStatusError); ok && t.negativeTTL > 0 &&
+			(se.StatusCode == http.StatusNotFound || se.StatusCode == http.StatusGone) {
+			if setErr := t.cache.Set(c, iri, &CachedDereference{
+				StatusCode: se.StatusCode,
+				Expires:    now.Add(t.negativeTTL),
+			}); setErr != nil {
+				return nil, setErr
+			}
+		}
+		return nil, err
+	}
+	if resp.NotModified {
+		resp.Body = d.Body
+	}
+	if expires, ok := t.expires(resp.Header, now); ok {
+		entry := &CachedDereference{
+			Body:         resp.Body,
+			ETag:         resp.Header.Get(etagHeader),
+			LastModified: resp.Header.Get(lastModifiedHeader),
+			Expires:      expires,
+		}
+		if resp.NotModified {
+			// A 304 response need not repeat the validators.
+			if len(entry.ETag) == 0 {
+				entry.ETag = d.ETag
+			}
+			if len(entry.LastModified) == 0 {
+				entry.LastModified = d.LastModified
+			}
+		}
+		if err = t.cache.Set(c, iri, entry); err != nil {
+			return nil, err
+		}
+	} else if err = t.cache.Delete(c, iri); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Invalidate removes the cached data for the IRI. It is called when the
+// federating protocol receives an Update or Delete of the IRI.
+func (t *CachingTransport) Invalidate(c context.Context, iri *url.URL) error {
+	return t.cache.Delete(c, iri)
+}
+
+// expires determines when a response expires from its headers, and whether it
+// may be cached at all.
+func (t *CachingTransport) expires(h http.Header, now time.Time) (time.Time, bool) {
+	for _, directive := range strings.Split(h.Get(cacheControlHeader), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store" || directive == "private":
+			return now, false
+		case directive == "no-cache":
+			// Cached only to be revalidated on every use.
+			return now, true
+		case strings.HasPrefix(directive, "max-age="):
+			if secs, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64); err == nil {
+				return now.Add(time.Duration(secs) * time.Second), true
+			}
+		}
+	}
+	if v := h.Get(expiresHeader); len(v) > 0 {
+		// A malformed Expires header means the response has already
+		// expired.
+		if e, err := http.ParseTime(v); err == nil {
+			return e, true
+		}
+		return now, true
+	}
+	return now.Add(t.ttl), true
+}
+
+// result returns the cached data, or the status error that was cached.
+func (d *CachedDereference) result(iri *url.URL) ([]byte, error) {
+	if d.StatusCode != 0 {
+		return nil, &HttpStatusError{
+			Method:     "GET",
+			IRI:        iri,
+			StatusCode: d.StatusCode,
+			Status:     http.StatusText(d.StatusCode),
+		}
+	}
+	return d.Body, nil
+}
@@ -0,0 +1,165 @@
+package pub
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// settableClock is a Clock whose time is set by the test.
+type settableClock struct {
+	now time.Time
+}
+
+func (s *settableClock) Now() time.Time {
+	return s.now
+}
+
+// conditionalTransport responds to dereferences with a status and headers,
+// recording the validators of each request.
+type conditionalTransport struct {
+	Transport
+	status int
+	body   string
+	header http.Header
+	calls  []string
+}
+
+func (f *conditionalTransport) Dereference(c context.Context, iri *url.URL) ([]byte, error) {
+	resp, err := f.DereferenceIfModified(c, iri, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (f *conditionalTransport) DereferenceIfModified(c context.Context, iri *url.URL, etag, lastModified string) (*DereferenceResponse, error) {
+	f.calls = append(f.calls, etag+lastModified)
+	switch {
+	case f.status == http.StatusNotModified && len(etag+lastModified) > 0:
+		return &DereferenceResponse{NotModified: true, Header: f.header}, nil
+	case f.status != http.StatusOK && f.status != http.StatusNotModified:
+		return nil, &HttpStatusError{Method: "GET", IRI: iri, StatusCode: f.status, Status: http.StatusText(f.status)}
+	}
+	return &DereferenceResponse{Body: []byte(f.body), Header: f.header}, nil
+}
+
+func TestCachingTransport(t *testing.T) {
+	iri, err := url.Parse(testKeyOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyIRI, err := url.Parse(testKeyId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type step struct {
+		advance    time.Duration
+		status     int
+		body       string
+		header     http.Header
+		invalidate bool
+		// Expectations
+		expectBody  string
+		expectErr   bool
+		expectCalls []string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "max-age",
+			steps: []step{
+				{status: http.StatusOK, body: "a", header: http.Header{"Cache-Control": {"max-age=60"}}, expectBody: "a", expectCalls: []string{""}},
+				{advance: 30 * time.Second, body: "b", expectBody: "a", expectCalls: []string{""}},
+				{advance: 31 * time.Second, status: http.StatusOK, body: "b", expectBody: "b", expectCalls: []string{"", ""}},
+			},
+		},
+		{
+			name: "revalidated with etag",
+			steps: []step{
+				{status: http.StatusOK, body: "a", header: http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}}, expectBody: "a", expectCalls: []string{""}},
+				{status: http.StatusNotModified, expectBody: "a", expectCalls: []string{"", `"v1"`}},
+			},
+		},
+		{
+			name: "not stored",
+			steps: []step{
+				{status: http.StatusOK, body: "a", header: http.Header{"Cache-Control": {"no-store"}}, expectBody: "a", expectCalls: []string{""}},
+				{status: http.StatusOK, body: "b", expectBody: "b", expectCalls: []string{"", ""}},
+			},
+		},
+		{
+			name: "negatively cached",
+			steps: []step{
+				{status: http.StatusGone, expectErr: true, expectCalls: []string{""}},
+				{advance: time.Minute, status: http.StatusOK, body: "a", expectErr: true, expectCalls: []string{""}},
+				{advance: time.Hour, status: http.StatusOK, body: "a", expectBody: "a", expectCalls: []string{"", ""}},
+			},
+		},
+		{
+			name: "invalidated",
+			steps: []step{
+				{status: http.StatusOK, body: "a", expectBody: "a", expectCalls: []string{""}},
+				{status: http.StatusOK, body: "b", invalidate: true, expectBody: "b", expectCalls: []string{"", ""}},
+			},
+		},
+	}
+	for _, test := range tests {
+		clock := &settableClock{now: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)}
+		inner := &conditionalTransport{}
+		tp := NewCachingTransport(inner, NewMemoryDereferenceCache(10), clock, 24*time.Hour, 5*time.Minute)
+		for i, s := range test.steps {
+			clock.now = clock.now.Add(s.advance)
+			inner.status, inner.body, inner.header = s.status, s.body, s.header
+			if s.invalidate {
+				if err := tp.Invalidate(context.Background(), keyIRI); err != nil {
+					t.Fatal(err)
+				}
+			}
+			b, err := tp.Dereference(context.Background(), iri)
+			if s.expectErr && err == nil {
+				t.Fatalf("(%q, %d): expected error, got none", test.name, i)
+			} else if !s.expectErr && err != nil {
+				t.Fatalf("(%q, %d): unexpected error: %v", test.name, i, err)
+			} else if string(b) != s.expectBody {
+				t.Fatalf("(%q, %d): expected %q, got %q", test.name, i, s.expectBody, string(b))
+			} else if len(inner.calls) != len(s.expectCalls) {
+				t.Fatalf("(%q, %d): expected %v, got %v", test.name, i, s.expectCalls, inner.calls)
+			}
+			for j := range s.expectCalls {
+				if inner.calls[j] != s.expectCalls[j] {
+					t.Fatalf("(%q, %d): expected %v, got %v", test.name, i, s.expectCalls, inner.calls)
+				}
+			}
+		}
+	}
+}
+
+func TestMemoryDereferenceCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	var iris []*url.URL
+	for _, s := range []string{"https://example.com/a", "https://example.com/b", "https://example.com/c"} {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		iris = append(iris, u)
+	}
+	c := context.Background()
+	m := NewMemoryDereferenceCache(2)
+	m.Set(c, iris[0], &CachedDereference{Body: []byte("a")})
+	m.Set(c, iris[1], &CachedDereference{Body: []byte("b")})
+	m.Get(c, iris[0])
+	m.Set(c, iris[2], &CachedDereference{Body: []byte("c")})
+	for i, expected := range []bool{true, false, true} {
+		d, err := m.Get(c, iris[i])
+		if err != nil {
+			t.Fatal(err)
+		} else if (d != nil) != expected {
+			t.Fatalf("(%q): expected cached %v, got %v", iris[i], expected, d != nil)
+		}
+	}
+}
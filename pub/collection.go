@@ -0,0 +1,143 @@
+package pub
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-fed/activity/streams/vocab"
+	"net/url"
+)
+
+const (
+	// defaultMaxCollectionPages is the number of pages followed when
+	// resolving the recipients in a collection.
+	defaultMaxCollectionPages = 100
+)
+
+// CollectionWalker visits the items of a Collection or OrderedCollection,
+// including those on its pages.
+//
+// Starting from a collection, its 'first' page is followed. Starting from a
+// page, or once on a page, each 'next' page is followed until there are no
+// more. Pages may be embedded or referenced by IRI, in which case they are
+// dereferenced with the Transport.
+type CollectionWalker struct {
+	// dereference fetches and resolves the type at an IRI.
+	dereference func(c context.Context, iri *url.URL) (vocab.Type, error)
+	maxPages    int
+}
+
+// NewCollectionWalker returns a CollectionWalker that dereferences with the
+// Transport, following at most maxPages pages.
+//
+// If maxPages is zero or negative, then pages are followed until there are no
+// more. A page seen before is never followed again, so that a collection
+// whose pages loop back on themselves is still walked only once.
+func NewCollectionWalker(t Transport, maxPages int) *CollectionWalker {
+	return newCollectionWalker(func(c context.Context, iri *url.URL) (vocab.Type, error) {
+		return dereferenceType(c, t, iri)
+	}, maxPages)
+}
+
+// newCollectionWalker returns a CollectionWalker that obtains the pages it
+// follows with the dereference function.
+func newCollectionWalker(dereference func(c context.Context, iri *url.URL) (vocab.Type, error), maxPages int) *CollectionWalker {
+	return &CollectionWalker{
+		dereference: dereference,
+		maxPages:    maxPages,
+	}
+}
+
+// Walk dereferences the collection or page at the IRI and calls fn with each
+// of its items, in order, stopping at the first error returned by fn.
+//
+// An error is returned if the context is done before the last page is
+// dereferenced.
+func (w *CollectionWalker) Walk(c context.Context, iri *url.URL, fn func(item IdProperty) error) error {
+	t, err := w.dereference(c, iri)
+	if err != nil {
+		return err
+	}
+	return w.walk(c, iri, t, fn)
+}
+
+// walk calls fn with the items of a collection or page that has already been
+// dereferenced, then follows its pages.
+func (w *CollectionWalker) walk(c context.Context, iri *url.URL, t vocab.Type, fn func(item IdProperty) error) error {
+	seen := map[string]bool{iri.String(): true}
+	if id, err := GetId(t); err == nil {
+		seen[id.String()] = true
+	}
+	for pages := 0; ; pages++ {
+		if err := forEachItem(t, fn); err != nil {
+			return err
+		}
+		var page IdProperty
+		if n, ok := t.(nexter); ok && n.GetActivityStreamsNext() != nil {
+			page = n.GetActivityStreamsNext()
+		} else if f, ok := t.(firster); ok && pages == 0 && f.GetActivityStreamsFirst() != nil {
+			page = f.GetActivityStreamsFirst()
+		} else {
+			return nil
+		}
+		if w.maxPages > 0 && pages >= w.maxPages {
+			return nil
+		} else if err := c.Err(); err != nil {
+			return err
+		}
+		pageIRI, err := ToId(page)
+		if err != nil {
+			return err
+		} else if seen[pageIRI.String()] {
+			return nil
+		}
+		seen[pageIRI.String()] = true
+		if t = page.GetType(); t == nil {
+			if t, err = w.dereference(c, pageIRI); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// dereferenceType fetches and resolves the type at the IRI with the Transport.
+func dereferenceType(c context.Context, t Transport, iri *url.URL) (vocab.Type, error) {
+	b, err := t.Dereference(c, iri)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return toType(c, m)
+}
+
+// isCollection determines whether the type is a collection or a page of one.
+func isCollection(t vocab.Type) bool {
+	switch t.(type) {
+	case itemser, orderedItemser, firster:
+		return true
+	}
+	return false
+}
+
+// forEachItem calls fn with each element of the 'items' or 'orderedItems'
+// property.
+func forEachItem(t vocab.Type, fn func(item IdProperty) error) error {
+	if v, ok := t.(itemser); ok && v.GetActivityStreamsItems() != nil {
+		i := v.GetActivityStreamsItems()
+		for iter := i.Begin(); iter != i.End(); iter = iter.Next() {
+			if err := fn(iter); err != nil {
+				return err
+			}
+		}
+	} else if v, ok := t.(orderedItemser); ok && v.GetActivityStreamsOrderedItems() != nil {
+		i := v.GetActivityStreamsOrderedItems()
+		for iter := i.Begin(); iter != i.End(); iter = iter.Next() {
+			if err := fn(iter); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
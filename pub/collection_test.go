@@ -0,0 +1,108 @@
+package pub
+
+import (
+	"context"
+	"github.com/go-test/deep"
+	"net/url"
+	"testing"
+)
+
+const (
+	testFollowers     = "https://example.com/users/alice/followers"
+	testFollowersPage = testFollowers + "?page="
+)
+
+// testPagedFollowers is a followers collection whose second page links back to
+// its first.
+var testPagedFollowers = map[string][]byte{
+	testFollowers:           []byte(`{"@context":"https://www.w3.org/ns/activitystreams","id":"` + testFollowers + `","type":"OrderedCollection","totalItems":3,"first":"` + testFollowersPage + `1"}`),
+	testFollowersPage + "1": []byte(`{"@context":"https://www.w3.org/ns/activitystreams","id":"` + testFollowersPage + `1","type":"OrderedCollectionPage","orderedItems":["https://example.net/users/bob","https://example.net/users/carol"],"next":"` + testFollowersPage + `2"}`),
+	testFollowersPage + "2": []byte(`{"@context":"https://www.w3.org/ns/activitystreams","id":"` + testFollowersPage + `2","type":"OrderedCollectionPage","orderedItems":["https://example.org/users/dave"],"next":"` + testFollowersPage + `1"}`),
+}
+
+func TestCollectionWalker(t *testing.T) {
+	followers, err := url.Parse(testFollowers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name     string
+		c        context.Context
+		maxPages int
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "all pages",
+			c:        context.Background(),
+			expected: []string{"https://example.net/users/bob", "https://example.net/users/carol", "https://example.org/users/dave"},
+		},
+		{
+			name:     "page cap",
+			c:        context.Background(),
+			maxPages: 1,
+			expected: []string{"https://example.net/users/bob", "https://example.net/users/carol"},
+		},
+		{
+			name:    "cancelled",
+			c:       cancelled,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		w := NewCollectionWalker(mapTransport{docs: testPagedFollowers}, test.maxPages)
+		var actual []string
+		err := w.Walk(test.c, followers, func(item IdProperty) error {
+			id, err := ToId(item)
+			if err != nil {
+				return err
+			}
+			actual = append(actual, id.String())
+			return nil
+		})
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("(%q): expected error, got none", test.name)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		if diff := deep.Equal(actual, test.expected); diff != nil {
+			t.Fatalf("(%q): %v", test.name, diff)
+		}
+	}
+}
+
+func TestResolveInboxesPagedCollection(t *testing.T) {
+	docs := make(map[string][]byte, len(testPagedFollowers)+3)
+	for k, v := range testPagedFollowers {
+		docs[k] = v
+	}
+	for _, id := range []string{"https://example.net/users/bob", "https://example.net/users/carol", "https://example.org/users/dave"} {
+		docs[id] = []byte(`{"@context":"https://www.w3.org/ns/activitystreams","id":"` + id + `","type":"Person","inbox":"` + id + `/inbox"}`)
+	}
+	followers, err := url.Parse(testFollowers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &sideEffectActor{db: newLocalActorsDatabase(t, nil)}
+	actors, err := a.resolveInboxes(context.Background(), mapTransport{docs: docs}, []*url.URL{followers}, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inboxes, err := getInboxes(actors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := make([]string, 0, len(inboxes))
+	for _, u := range inboxes {
+		actual = append(actual, u.String())
+	}
+	expected := []string{"https://example.net/users/bob/inbox", "https://example.net/users/carol/inbox", "https://example.org/users/dave/inbox"}
+	if diff := deep.Equal(actual, expected); diff != nil {
+		t.Fatal(diff)
+	}
+}
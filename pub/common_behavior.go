@@ -27,6 +27,10 @@ type CommonBehavior interface {
 	// Finally, if the authentication and authorization succeeds, then
 	// shouldReturn must be false and error nil. The request will continue
 	// to be processed.
+	//
+	// If the Actor was created WithAuthorizedFetch, RequesterFromContext
+	// returns the actor that signed the request. A BearerAuthenticator may
+	// be used to authenticate requests with OAuth2 bearer tokens instead.
 	AuthenticateGetInbox(c context.Context, w http.ResponseWriter, r *http.Request) (shouldReturn bool, err error)
 	// AuthenticateGetOutbox delegates the authentication of a GET to an
 	// outbox.
@@ -47,5 +51,9 @@ type CommonBehavior interface {
 	// Finally, if the authentication and authorization succeeds, then
 	// shouldReturn must be false and error nil. The request will continue
 	// to be processed.
+	//
+	// If the Actor was created WithAuthorizedFetch, RequesterFromContext
+	// returns the actor that signed the request. A BearerAuthenticator may
+	// be used to authenticate requests with OAuth2 bearer tokens instead.
 	AuthenticateGetOutbox(c context.Context, w http.ResponseWriter, r *http.Request) (shouldReturn bool, err error)
 }
@@ -3,6 +3,7 @@ package pub
 import (
 	"context"
 	"github.com/go-fed/activity/streams/vocab"
+	"net/http"
 	"net/url"
 )
 
@@ -62,6 +63,9 @@ type Database interface {
 	Exists(c context.Context, id *url.URL) (exists bool, err error)
 	// Get returns the database entry for the specified id.
 	//
+	// When serving a request with NewAuthorizedFetchHandler,
+	// RequesterFromContext returns the actor that signed the request.
+	//
 	// The library makes this call only after acquiring a lock first.
 	Get(c context.Context, id *url.URL) (value vocab.Type, err error)
 	// Create adds a new entry to the database which must be able to be
@@ -134,3 +138,95 @@ type Database interface {
 	// The library makes this call only after acquiring a lock first.
 	Liked(c context.Context, actorIRI *url.URL) (followers vocab.ActivityStreamsCollection, err error)
 }
+
+// LocalFollowersDatabase is an optional Database capability used to fan out
+// activities received by the shared inbox to the followers of their actor.
+//
+// Without it, activities received by the shared inbox are only delivered to
+// the local actors they explicitly address.
+type LocalFollowersDatabase interface {
+	// LocalFollowers returns the IRIs of the actors owned by this server
+	// that follow the actor with the given id, which is usually a peer's.
+	//
+	// LocalFollowers is called without acquiring a lock.
+	LocalFollowers(c context.Context, actorIRI *url.URL) (followers []*url.URL, err error)
+}
+
+// InboxAppender is an optional Database capability used to add an activity to
+// an inbox without reading and writing back the inbox with GetInbox and
+// SetInbox, whose cost grows with the size of the inbox.
+type InboxAppender interface {
+	// AppendToInbox adds the activity with the given id as the most recent
+	// item of the inbox, unless the inbox already contains it. It returns
+	// whether the activity was added.
+	//
+	// AppendToInbox is called without acquiring a lock, so the check and
+	// the addition must be done atomically.
+	AppendToInbox(c context.Context, inboxIRI, id *url.URL) (appended bool, err error)
+}
+
+// OutboxAppender is an optional Database capability used to add an activity to
+// an outbox without reading and writing back the outbox with GetOutbox and
+// SetOutbox, whose cost grows with the size of the outbox.
+type OutboxAppender interface {
+	// AppendToOutbox adds the activity with the given id as the most
+	// recent item of the outbox.
+	//
+	// AppendToOutbox is called without acquiring a lock.
+	AppendToOutbox(c context.Context, outboxIRI, id *url.URL) error
+}
+
+// BoxPage is a page of the items of an inbox or outbox, as returned by an
+// InboxPager or OutboxPager.
+type BoxPage struct {
+	// Items are the ids of the activities on the page, most recent first.
+	Items []*url.URL
+	// Next is the cursor of the page of less recent items, or empty if
+	// there are none.
+	Next string
+	// Prev is the cursor of the page of more recent items, or empty if
+	// there are none.
+	Prev string
+}
+
+// InboxPager is an optional Database capability used to serve inboxes a page at
+// a time. When implemented, GET requests to an inbox are served an
+// OrderedCollection linking to its first OrderedCollectionPage, instead of the
+// page returned by the FederatingProtocol's GetInbox.
+//
+// GetInbox is then never called, so any filtering it applies, such as hiding
+// items from the RequesterFromContext, must be applied by InboxTotalItems and
+// InboxPage instead. They are passed the same context.
+//
+// The pages are found with cursors, which are opaque to the library. Their
+// format is up to the implementation, such as the id of the last item of the
+// previous page along with the direction to page in. A cursor that is not
+// valid is reported with ErrInvalidCursor.
+type InboxPager interface {
+	// InboxIRI returns the IRI of the inbox requested, which identifies
+	// the collection and its pages. The URL of a request received by a
+	// server usually has neither its scheme nor its host.
+	InboxIRI(c context.Context, r *http.Request) (inboxIRI *url.URL, err error)
+	// InboxTotalItems returns the number of items in the inbox.
+	InboxTotalItems(c context.Context, inboxIRI *url.URL) (n int, err error)
+	// InboxPage returns up to limit items of the inbox, starting at the
+	// cursor. The cursor is empty for the page of the most recent items.
+	InboxPage(c context.Context, inboxIRI *url.URL, cursor string, limit int) (page BoxPage, err error)
+}
+
+// OutboxPager is an optional Database capability used to serve outboxes a page
+// at a time, in the same way as an InboxPager serves inboxes.
+//
+// The SocialProtocol's GetOutbox is then never called, so any filtering it
+// applies, such as hiding items that are not public, must be applied by
+// OutboxTotalItems and OutboxPage instead.
+type OutboxPager interface {
+	// OutboxIRI returns the IRI of the outbox requested, in the same way
+	// as InboxIRI.
+	OutboxIRI(c context.Context, r *http.Request) (outboxIRI *url.URL, err error)
+	// OutboxTotalItems returns the number of items in the outbox.
+	OutboxTotalItems(c context.Context, outboxIRI *url.URL) (n int, err error)
+	// OutboxPage returns up to limit items of the outbox, starting at the
+	// cursor. The cursor is empty for the page of the most recent items.
+	OutboxPage(c context.Context, outboxIRI *url.URL, cursor string, limit int) (page BoxPage, err error)
+}
@@ -207,3 +207,31 @@ type DelegateActor interface {
 	// API is enabled.
 	GetInbox(c context.Context, r *http.Request) (vocab.ActivityStreamsOrderedCollectionPage, error)
 }
+
+// SharedInboxDelegate is implemented by a DelegateActor that supports a shared
+// inbox for all of the actors on its server. The DelegateActor of an Actor
+// created by NewFederatingActor or NewActor implements it.
+type SharedInboxDelegate interface {
+	// SharedInboxRecipients returns the inboxes of the local actors that an
+	// activity POSTed to the shared inbox is for.
+	SharedInboxRecipients(c context.Context, activity Activity) (inboxes []*url.URL, err error)
+}
+
+// PagedBoxDelegate is implemented by a DelegateActor that serves inboxes and
+// outboxes a page at a time. The DelegateActor of an Actor created by
+// NewSocialActor, NewFederatingActor, or NewActor implements it, and pages
+// when its Database is an InboxPager or OutboxPager.
+type PagedBoxDelegate interface {
+	// GetPagedInbox returns the inbox of the request, as an
+	// OrderedCollection, or as one of its OrderedCollectionPages if the
+	// request asks for a page. If paged is false, the inbox is not paged
+	// and GetInbox is used instead.
+	//
+	// AuthenticateGetInbox will be called prior to this.
+	GetPagedInbox(c context.Context, r *http.Request) (t vocab.Type, paged bool, err error)
+	// GetPagedOutbox returns the outbox of the request in the same way as
+	// GetPagedInbox.
+	//
+	// AuthenticateGetOutbox will be called prior to this.
+	GetPagedOutbox(c context.Context, r *http.Request) (t vocab.Type, paged bool, err error)
+}
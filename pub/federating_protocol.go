@@ -29,6 +29,15 @@ type FederatingProtocol interface {
 	// Finally, if the authentication and authorization succeeds, then
 	// shouldReturn must be false and error nil. The request will continue
 	// to be processed.
+	//
+	// The actor verified as the sender of an authentic request must be
+	// recorded with SetRequester. Objects embedded in the activity are
+	// checked against the sender's host, and activities embedding objects
+	// cannot be processed without one unless their origin policy is
+	// OriginTrust.
+	//
+	// An HttpSigAuthenticator may be used to authenticate requests with
+	// HTTP Signatures.
 	AuthenticatePostInbox(c context.Context, w http.ResponseWriter, r *http.Request) (shouldReturn bool, err error)
 	// Blocked should determine whether to permit a set of actors given by
 	// their ids are able to interact with this particular end user due to
@@ -38,6 +38,10 @@ type FederatingWrappedCallbacks struct {
 	//
 	// Create calls Create for each object in the federated Activity.
 	Create func(context.Context, vocab.ActivityStreamsCreate) error
+	// CreateOrigin determines what to do with objects embedded in a Create
+	// that are not on the host of the peer that sent it. It is
+	// OriginDereference unless set.
+	CreateOrigin OriginPolicy
 	// Update handles additional side effects for the Update ActivityStreams
 	// type, specific to the application using go-fed.
 	//
@@ -47,6 +51,10 @@ type FederatingWrappedCallbacks struct {
 	// Update calls Update on the federated entry from the database, with a
 	// new value.
 	Update func(context.Context, vocab.ActivityStreamsUpdate) error
+	// UpdateOrigin determines what to do with objects embedded in an Update
+	// that are not on the host of the peer that sent it. It is
+	// OriginDereference unless set.
+	UpdateOrigin OriginPolicy
 	// Delete handles additional side effects for the Delete ActivityStreams
 	// type, specific to the application using go-fed.
 	//
@@ -104,6 +112,10 @@ type FederatingWrappedCallbacks struct {
 	// The wrapping function will add the activity to the "shares"
 	// collection on all 'object' targets owned by this server.
 	Announce func(context.Context, vocab.ActivityStreamsAnnounce) error
+	// AnnounceOrigin determines what to do with objects embedded in an
+	// Announce that are not on the host of the peer that sent it. It is
+	// OriginDereference unless set.
+	AnnounceOrigin OriginPolicy
 	// Undo handles additional side effects for the Undo ActivityStreams
 	// type, specific to the application using go-fed.
 	//
@@ -123,6 +135,13 @@ type FederatingWrappedCallbacks struct {
 	// received from a federated peer, as delivering Blocks explicitly
 	// deviates from the original ActivityPub specification.
 	Block func(context.Context, vocab.ActivityStreamsBlock) error
+	// Default handles the side effects of activities that no other
+	// callback handles, including activities of types unknown to go-fed,
+	// which are received as an *UnknownActivity.
+	//
+	// The wrapping function provides no default side effects. The activity
+	// has already been added to the inbox, and is forwarded like any other.
+	Default func(context.Context, Activity) error
 
 	// Sidechannel data -- this is set at request handling time. These must
 	// be set before the callbacks are used.
@@ -133,6 +152,8 @@ type FederatingWrappedCallbacks struct {
 	inboxIRI *url.URL
 	// newTransport creates a new Transport.
 	newTransport func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (t Transport, err error)
+	// deliverer, if set, schedules the delivery of responses.
+	deliverer Deliverer
 }
 
 // disjoint ensures that the functions given do not share a type signature with
@@ -200,6 +221,15 @@ func (w FederatingWrappedCallbacks) create(c context.Context, a vocab.ActivitySt
 	if op == nil || op.Len() == 0 {
 		return ErrObjectRequired
 	}
+	checked, err := w.applyOriginPolicy(c, a, w.CreateOrigin)
+	if err != nil {
+		return err
+	}
+	a, ok := checked.(vocab.ActivityStreamsCreate)
+	if !ok {
+		return fmt.Errorf("activity is no longer a Create: %T", checked)
+	}
+	op = a.GetActivityStreamsObject()
 	// Create anonymous loop function to be able to properly scope the defer
 	// for the database lock at each iteration.
 	loopFn := func(iter vocab.ActivityStreamsObjectPropertyIterator) error {
@@ -210,7 +240,8 @@ func (w FederatingWrappedCallbacks) create(c context.Context, a vocab.ActivitySt
 		}
 		id, err := GetId(t)
 		if err != nil {
-			return err
+			// A transient object, without an id, is not stored.
+			return nil
 		}
 		err = w.db.Lock(c, id)
 		if err != nil {
@@ -242,6 +273,15 @@ func (w FederatingWrappedCallbacks) update(c context.Context, a vocab.ActivitySt
 	if err := mustHaveActivityOriginMatchObjects(a); err != nil {
 		return err
 	}
+	checked, err := w.applyOriginPolicy(c, a, w.UpdateOrigin)
+	if err != nil {
+		return err
+	}
+	a, ok := checked.(vocab.ActivityStreamsUpdate)
+	if !ok {
+		return fmt.Errorf("activity is no longer an Update: %T", checked)
+	}
+	op = a.GetActivityStreamsObject()
 	// Create anonymous loop function to be able to properly scope the defer
 	// for the database lock at each iteration.
 	loopFn := func(iter vocab.ActivityStreamsObjectPropertyIterator) error {
@@ -261,7 +301,7 @@ func (w FederatingWrappedCallbacks) update(c context.Context, a vocab.ActivitySt
 		if err := w.db.Update(c, t); err != nil {
 			return err
 		}
-		return nil
+		return w.invalidate(c, id)
 	}
 	for iter := op.Begin(); iter != op.End(); iter = iter.Next() {
 		if err := loopFn(iter); err != nil {
@@ -298,7 +338,7 @@ func (w FederatingWrappedCallbacks) deleteFn(c context.Context, a vocab.Activity
 		if err := w.db.Delete(c, id); err != nil {
 			return err
 		}
-		return nil
+		return w.invalidate(c, id)
 	}
 	for iter := op.Begin(); iter != op.End(); iter = iter.Next() {
 		if err := loopFn(iter); err != nil {
@@ -311,6 +351,19 @@ func (w FederatingWrappedCallbacks) deleteFn(c context.Context, a vocab.Activity
 	return nil
 }
 
+// invalidate removes any data cached for the IRI by the Transport, if it is an
+// Invalidator such as a CachingTransport.
+func (w FederatingWrappedCallbacks) invalidate(c context.Context, iri *url.URL) error {
+	t, err := w.newTransport(c, w.inboxIRI, goFedUserAgent())
+	if err != nil {
+		return err
+	}
+	if i, ok := t.(Invalidator); ok {
+		return i.Invalidate(c, iri)
+	}
+	return nil
+}
+
 // follow implements the federating Follow activity side effects.
 func (w FederatingWrappedCallbacks) follow(c context.Context, a vocab.ActivityStreamsFollow) error {
 	op := a.GetActivityStreamsObject()
@@ -411,12 +464,18 @@ func (w FederatingWrappedCallbacks) follow(c context.Context, a vocab.ActivitySt
 		if err != nil {
 			return err
 		}
-		t, err := w.newTransport(c, w.inboxIRI, goFedUserAgent())
-		if err != nil {
-			return err
-		}
-		if err := t.BatchDeliver(c, b, recipients); err != nil {
-			return err
+		if w.deliverer != nil {
+			scheduleDeliveries(w.deliverer, w.newTransport, w.inboxIRI, b, recipients)
+		} else {
+			t, err := w.newTransport(c, w.inboxIRI, goFedUserAgent())
+			if err != nil {
+				return err
+			}
+			// A BatchDeliveryError names the peers that failed, so
+			// that the caller is able to retry them.
+			if err := t.BatchDeliver(c, b, recipients); err != nil {
+				return err
+			}
 		}
 	}
 	if w.Follow != nil {
@@ -651,6 +710,14 @@ func (w FederatingWrappedCallbacks) announce(c context.Context, a vocab.Activity
 	if err != nil {
 		return err
 	}
+	checked, err := w.applyOriginPolicy(c, a, w.AnnounceOrigin)
+	if err != nil {
+		return err
+	}
+	a, ok := checked.(vocab.ActivityStreamsAnnounce)
+	if !ok {
+		return fmt.Errorf("activity is no longer an Announce: %T", checked)
+	}
 	op := a.GetActivityStreamsObject()
 	// Create anonymous loop function to be able to properly scope the defer
 	// for the database lock at each iteration.
@@ -753,3 +820,12 @@ func (w FederatingWrappedCallbacks) block(c context.Context, a vocab.ActivityStr
 	}
 	return nil
 }
+
+// defaultFn implements the federating side effects of activities no other
+// callback handles.
+func (w FederatingWrappedCallbacks) defaultFn(c context.Context, a Activity) error {
+	if w.Default != nil {
+		return w.Default(c, a)
+	}
+	return nil
+}
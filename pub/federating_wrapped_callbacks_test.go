@@ -0,0 +1,139 @@
+package pub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-fed/activity/streams/vocab"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// lockingBoxOwnerDatabase is a boxOwnerDatabase that permits locking.
+type lockingBoxOwnerDatabase struct {
+	boxOwnerDatabase
+}
+
+func (lockingBoxOwnerDatabase) Lock(c context.Context, id *url.URL) error   { return nil }
+func (lockingBoxOwnerDatabase) Unlock(c context.Context, id *url.URL) error { return nil }
+
+// failingBatchTransport fails every batch delivery, counting them.
+type failingBatchTransport struct {
+	Transport
+	count *int
+}
+
+func (f failingBatchTransport) BatchDeliver(c context.Context, b []byte, recipients []*url.URL) error {
+	*f.count++
+	errs := make(map[string]error, len(recipients))
+	for _, r := range recipients {
+		errs[r.String()] = fmt.Errorf("unreachable")
+	}
+	return &BatchDeliveryError{Errors: errs}
+}
+
+func TestFollowReturnsBatchDeliveryError(t *testing.T) {
+	owner, err := url.Parse("https://example.org/users/bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbox, err := url.Parse("https://example.org/users/bob/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]interface{}
+	raw := `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/follows/1","type":"Follow","actor":"https://example.com/users/alice","object":"https://example.org/users/bob"}`
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		t.Fatal(err)
+	}
+	a, err := toType(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	w := FederatingWrappedCallbacks{
+		OnFollow: OnFollowAutomaticallyReject,
+		db:       lockingBoxOwnerDatabase{boxOwnerDatabase{owner: owner}},
+		inboxIRI: inbox,
+		newTransport: func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+			return failingBatchTransport{count: &count}, nil
+		},
+	}
+	err = w.follow(context.Background(), a.(vocab.ActivityStreamsFollow))
+	if _, ok := err.(*BatchDeliveryError); !ok {
+		t.Fatalf("expected *BatchDeliveryError, got %v", err)
+	} else if count != 1 {
+		t.Fatalf("expected 1 batch delivery, got %d", count)
+	}
+}
+
+// updatingDatabase permits updating and deleting anything.
+type updatingDatabase struct {
+	Database
+}
+
+func (updatingDatabase) Lock(c context.Context, id *url.URL) error    { return nil }
+func (updatingDatabase) Unlock(c context.Context, id *url.URL) error  { return nil }
+func (updatingDatabase) Update(c context.Context, t vocab.Type) error { return nil }
+func (updatingDatabase) Delete(c context.Context, id *url.URL) error  { return nil }
+
+func TestUpdateAndDeleteInvalidateCache(t *testing.T) {
+	const (
+		actor  = `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/users/alice","type":"Person","name":"Alice"}`
+		update = `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/updates/1","type":"Update","actor":"https://example.com/users/alice","object":` + actor + `}`
+		del    = `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/deletes/1","type":"Delete","actor":"https://example.com/users/alice","object":"https://example.com/users/alice"}`
+	)
+	iri, err := url.Parse("https://example.com/users/alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		activity string
+	}{
+		{
+			name:     "update",
+			activity: update,
+		},
+		{
+			name:     "delete",
+			activity: del,
+		},
+	}
+	for _, test := range tests {
+		cache := NewMemoryDereferenceCache(10)
+		tp := NewCachingTransport(mapTransport{docs: map[string][]byte{iri.String(): []byte(actor)}}, cache, fixedClock(time.Now()), time.Hour, 0)
+		if _, err := tp.Dereference(context.Background(), iri); err != nil {
+			t.Fatal(err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(test.activity), &m); err != nil {
+			t.Fatal(err)
+		}
+		a, err := toType(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := FederatingWrappedCallbacks{
+			UpdateOrigin: OriginTrust,
+			db:           updatingDatabase{},
+			newTransport: func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+				return tp, nil
+			},
+		}
+		switch v := a.(type) {
+		case vocab.ActivityStreamsUpdate:
+			err = w.update(context.Background(), v)
+		case vocab.ActivityStreamsDelete:
+			err = w.deleteFn(context.Background(), v)
+		}
+		if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if d, err := cache.Get(context.Background(), iri); err != nil {
+			t.Fatal(err)
+		} else if d != nil {
+			t.Fatalf("(%q): expected the cached actor to be invalidated", test.name)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package pub
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// FetchPolicy restricts the requests an HttpClient will make on behalf of the
+// library. Peers control which IRIs are dereferenced and delivered to, so
+// without one, a malicious peer may direct requests at services internal to
+// the application's network, or respond with more data than the application
+// can hold.
+//
+// Each kind of rejected request has its own error type: *DisallowedSchemeError,
+// *DisallowedPortError, *DisallowedAddressError, *ResponseTooLargeError, and
+// *TooManyRedirectsError.
+type FetchPolicy struct {
+	// Schemes that may be requested. If empty, only https is permitted.
+	Schemes []string
+	// Ports that may be requested. If empty, any port is permitted.
+	Ports []int
+	// AllowPrivateAddresses permits requests to loopback, private,
+	// link-local, and other addresses not reachable on the public
+	// internet. The address is checked after the host name is resolved, so
+	// a peer cannot evade the check with its DNS records.
+	AllowPrivateAddresses bool
+	// MaxResponseSize is the largest response body, in bytes, that may be
+	// read. If zero or negative, there is no limit.
+	MaxResponseSize int64
+	// MaxRedirects is the number of redirects that are followed. If zero
+	// or negative, redirects are not followed.
+	MaxRedirects int
+}
+
+// DefaultFetchPolicy only permits https requests to public addresses, with up
+// to 1 MiB response bodies and 3 redirects.
+//
+// Applications federating with peers served over plain http, or on private
+// addresses, must create their HttpClient with NewPolicyHttpClient and a
+// FetchPolicy permitting them, or opt out with WithoutFetchPolicy.
+func DefaultFetchPolicy() FetchPolicy {
+	return FetchPolicy{
+		Schemes:         []string{"https"},
+		MaxResponseSize: 1 << 20,
+		MaxRedirects:    3,
+	}
+}
+
+// NewPolicyHttpClient returns an http.Client that enforces the policy on every
+// request, including each redirect, for use by an HttpSigTransport.
+//
+// It does not use proxies from the environment, since a proxy would make the
+// connection instead. The client should be created once and shared, so that
+// connections are reused.
+func NewPolicyHttpClient(p FetchPolicy, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &policyRoundTripper{
+			policy: p,
+			next:   p.newTransport(),
+		},
+		CheckRedirect: p.checkRedirect,
+		Timeout:       timeout,
+	}
+}
+
+// WithoutFetchPolicy opts the client out of the DefaultFetchPolicy that an
+// HttpSigTransport otherwise enforces on it.
+//
+// It is intended for clients that are already restricted some other way, such
+// as by an egress proxy, and for tests.
+func WithoutFetchPolicy(client HttpClient) HttpClient {
+	return unrestrictedHttpClient{HttpClient: client}
+}
+
+// unrestrictedHttpClient marks a client opted out of the DefaultFetchPolicy.
+type unrestrictedHttpClient struct {
+	HttpClient
+}
+
+// restrictHttpClient enforces the DefaultFetchPolicy on a client, unless it was
+// created by NewPolicyHttpClient or WithoutFetchPolicy.
+//
+// An http.Client with the default http.Transport is given the same connection
+// checks as one created by NewPolicyHttpClient. Otherwise, the connection is
+// outside of the library's control, so only the IRI of each request and
+// redirect, including an IP address as its host, and the size of the response
+// are checked.
+//
+// Any other HttpClient may follow redirects without the policy seeing them, so
+// ErrUnrestrictedHttpClient is returned for it.
+func restrictHttpClient(client HttpClient) (HttpClient, error) {
+	p := DefaultFetchPolicy()
+	switch c := client.(type) {
+	case unrestrictedHttpClient:
+		return c.HttpClient, nil
+	case *http.Client:
+		if _, ok := c.Transport.(*policyRoundTripper); ok {
+			return c, nil
+		}
+		next := c.Transport
+		if next == nil || next == http.DefaultTransport {
+			next = p.newTransport()
+		}
+		return &http.Client{
+			Transport: &policyRoundTripper{
+				policy: p,
+				next:   next,
+			},
+			CheckRedirect: p.checkRedirect,
+			Jar:           c.Jar,
+			Timeout:       c.Timeout,
+		}, nil
+	default:
+		return nil, ErrUnrestrictedHttpClient
+	}
+}
+
+// newTransport returns an http.Transport that checks the addresses it
+// connects to, and does not use proxies from the environment.
+func (p FetchPolicy) newTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   p.control,
+	}
+	return &http.Transport{
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// checkURL ensures the scheme, port, and any IP address of the request are
+// permitted.
+func (p FetchPolicy) checkURL(u *url.URL) error {
+	schemes := p.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"https"}
+	}
+	allowed := false
+	for _, s := range schemes {
+		if s == u.Scheme {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return &DisallowedSchemeError{IRI: u}
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !p.AllowPrivateAddresses && !isPublicIP(ip) {
+		return &DisallowedAddressError{Address: u.Host}
+	}
+	if len(p.Ports) == 0 {
+		return nil
+	}
+	port, err := strconv.Atoi(u.Port())
+	if len(u.Port()) == 0 {
+		port, err = net.LookupPort("tcp", u.Scheme)
+	}
+	if err == nil {
+		for _, allowed := range p.Ports {
+			if allowed == port {
+				return nil
+			}
+		}
+	}
+	return &DisallowedPortError{IRI: u, Port: port}
+}
+
+// control rejects connections to non-public addresses once the host name has
+// been resolved.
+func (p FetchPolicy) control(network, address string, c syscall.RawConn) error {
+	if p.AllowPrivateAddresses {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return &DisallowedAddressError{Address: address}
+	}
+	return nil
+}
+
+// checkRedirect stops following redirects after the maximum.
+func (p FetchPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > p.MaxRedirects {
+		return &TooManyRedirectsError{IRI: req.URL, Limit: p.MaxRedirects}
+	}
+	return nil
+}
+
+// nonPublicNetworks are the address ranges that are not reachable on the
+// public internet, beyond those the net package identifies.
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "This" network
+	"10.0.0.0/8",    // Private
+	"100.64.0.0/10", // Carrier-grade NAT
+	"172.16.0.0/12", // Private
+	"192.0.0.0/24",  // IETF protocol assignments
+	"192.168.0.0/16",
+	"198.18.0.0/15", // Benchmarking
+	"240.0.0.0/4",   // Reserved
+	"fc00::/7",      // Unique local
+	"64:ff9b::/96",  // IPv4/IPv6 translation
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	n := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		n = append(n, ipNet)
+	}
+	return n
+}
+
+// isPublicIP determines whether the address is reachable on the public
+// internet.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range nonPublicNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// policyRoundTripper enforces the scheme, port, and response size limits of a
+// FetchPolicy.
+type policyRoundTripper struct {
+	policy FetchPolicy
+	next   http.RoundTripper
+}
+
+// Do sends the request as RoundTrip does, so that the policy is able to wrap
+// an HttpClient.
+func (p *policyRoundTripper) Do(req *http.Request) (*http.Response, error) {
+	return p.RoundTrip(req)
+}
+
+// RoundTrip checks the request is permitted before sending it, and limits how
+// much of the response body may be read.
+func (p *policyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := p.policy.checkURL(req.URL); err != nil {
+		return nil, err
+	}
+	resp, err := p.next.RoundTrip(req)
+	if err != nil || p.policy.MaxResponseSize <= 0 {
+		return resp, err
+	}
+	if resp.ContentLength > p.policy.MaxResponseSize {
+		resp.Body.Close()
+		return nil, &ResponseTooLargeError{IRI: req.URL, Limit: p.policy.MaxResponseSize}
+	}
+	resp.Body = &limitedBody{
+		ReadCloser: resp.Body,
+		iri:        req.URL,
+		limit:      p.policy.MaxResponseSize,
+		remaining:  p.policy.MaxResponseSize,
+	}
+	return resp, nil
+}
+
+// limitedBody fails reads once more than the limit has been read.
+type limitedBody struct {
+	io.ReadCloser
+	iri       *url.URL
+	limit     int64
+	remaining int64
+}
+
+// Read reads up to one byte past the limit, in order to detect a body that
+// exceeds it.
+func (l *limitedBody) Read(b []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, &ResponseTooLargeError{IRI: l.iri, Limit: l.limit}
+	}
+	if int64(len(b)) > l.remaining+1 {
+		b = b[:l.remaining+1]
+	}
+	n, err := l.ReadCloser.Read(b)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return 0, &ResponseTooLargeError{IRI: l.iri, Limit: l.limit}
+	}
+	return n, err
+}
+
+// unwrapFetchError returns the FetchPolicy error within the errors an
+// http.Client wraps it in, or the error unchanged if there is none.
+func unwrapFetchError(err error) error {
+	for e := err; e != nil; {
+		switch v := e.(type) {
+		case *DisallowedSchemeError, *DisallowedPortError, *DisallowedAddressError, *ResponseTooLargeError, *TooManyRedirectsError:
+			return v
+		case *url.Error:
+			e = v.Err
+		case *net.OpError:
+			e = v.Err
+		default:
+			return err
+		}
+	}
+	return err
+}
+
+// DisallowedSchemeError is returned when a FetchPolicy does not permit the
+// scheme of an IRI.
+type DisallowedSchemeError struct {
+	IRI *url.URL
+}
+
+// Error returns a description of the disallowed scheme.
+func (e *DisallowedSchemeError) Error() string {
+	return fmt.Sprintf("fetch policy does not permit scheme %q of %s", e.IRI.Scheme, e.IRI)
+}
+
+// DisallowedPortError is returned when a FetchPolicy does not permit the port
+// of an IRI.
+type DisallowedPortError struct {
+	IRI  *url.URL
+	Port int
+}
+
+// Error returns a description of the disallowed port.
+func (e *DisallowedPortError) Error() string {
+	return fmt.Sprintf("fetch policy does not permit port %d of %s", e.Port, e.IRI)
+}
+
+// DisallowedAddressError is returned when a FetchPolicy does not permit
+// connecting to the address a host name resolved to.
+type DisallowedAddressError struct {
+	Address string
+}
+
+// Error returns a description of the disallowed address.
+func (e *DisallowedAddressError) Error() string {
+	return fmt.Sprintf("fetch policy does not permit connecting to non-public address %s", e.Address)
+}
+
+// ResponseTooLargeError is returned when a response body is larger than a
+// FetchPolicy permits.
+type ResponseTooLargeError struct {
+	IRI   *url.URL
+	Limit int64
+}
+
+// Error returns a description of the response that was too large.
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response from %s is larger than the fetch policy limit of %d bytes", e.IRI, e.Limit)
+}
+
+// TooManyRedirectsError is returned when a request is redirected more times
+// than a FetchPolicy permits.
+type TooManyRedirectsError struct {
+	IRI   *url.URL
+	Limit int
+}
+
+// Error returns a description of the redirects that were not followed.
+func (e *TooManyRedirectsError) Error() string {
+	return fmt.Sprintf("redirect to %s exceeds the fetch policy limit of %d redirects", e.IRI, e.Limit)
+}
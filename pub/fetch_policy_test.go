@@ -0,0 +1,245 @@
+package pub
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchPolicy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/actor", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentTypeHeader, activityJSONMediaType)
+		w.Write([]byte(`{"type":"Person"}`))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 2048)))
+	})
+	mux.HandleFunc("/redirect/", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+		if n == 0 {
+			http.Redirect(w, r, "/actor", http.StatusFound)
+		} else {
+			http.Redirect(w, r, "/redirect/"+strconv.Itoa(n-1), http.StatusFound)
+		}
+	})
+	s := httptest.NewServer(mux)
+	defer s.Close()
+	privKey, _ := newTestKeyPair(t)
+	permissive := FetchPolicy{
+		Schemes:               []string{"http"},
+		AllowPrivateAddresses: true,
+		MaxResponseSize:       1024,
+		MaxRedirects:          1,
+	}
+	tests := []struct {
+		name    string
+		policy  FetchPolicy
+		path    string
+		checkFn func(err error) bool
+	}{
+		{
+			name:    "permitted",
+			policy:  permissive,
+			path:    "/actor",
+			checkFn: func(err error) bool { return err == nil },
+		},
+		{
+			name:    "permitted redirect",
+			policy:  permissive,
+			path:    "/redirect/0",
+			checkFn: func(err error) bool { return err == nil },
+		},
+		{
+			name:   "too many redirects",
+			policy: permissive,
+			path:   "/redirect/1",
+			checkFn: func(err error) bool {
+				_, ok := err.(*TooManyRedirectsError)
+				return ok
+			},
+		},
+		{
+			name:   "too large",
+			policy: permissive,
+			path:   "/large",
+			checkFn: func(err error) bool {
+				_, ok := err.(*ResponseTooLargeError)
+				return ok
+			},
+		},
+		{
+			name:   "private address",
+			policy: FetchPolicy{Schemes: []string{"http"}},
+			path:   "/actor",
+			checkFn: func(err error) bool {
+				_, ok := err.(*DisallowedAddressError)
+				return ok
+			},
+		},
+		{
+			name:   "scheme",
+			policy: FetchPolicy{AllowPrivateAddresses: true},
+			path:   "/actor",
+			checkFn: func(err error) bool {
+				_, ok := err.(*DisallowedSchemeError)
+				return ok
+			},
+		},
+		{
+			name:   "port",
+			policy: FetchPolicy{Schemes: []string{"http"}, Ports: []int{443}, AllowPrivateAddresses: true},
+			path:   "/actor",
+			checkFn: func(err error) bool {
+				_, ok := err.(*DisallowedPortError)
+				return ok
+			},
+		},
+	}
+	for _, test := range tests {
+		iri, err := url.Parse(s.URL + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := NewPolicyHttpClient(test.policy, time.Second)
+		tp, err := NewHttpSigTransport(client, "app", "go-fed", fixedClock(time.Now()), nil, testKeyId, privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tp.Dereference(context.Background(), iri); !test.checkFn(err) {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+	}
+}
+
+// redirectingRoundTripper redirects every request to a location.
+type redirectingRoundTripper struct {
+	location string
+}
+
+func (r redirectingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusFound,
+		Status:     http.StatusText(http.StatusFound),
+		Body:       http.NoBody,
+		Header:     http.Header{"Location": {r.location}},
+		Request:    req,
+	}, nil
+}
+
+func TestHttpSigTransportDefaultFetchPolicy(t *testing.T) {
+	privKey, _ := newTestKeyPair(t)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(contentTypeHeader, activityJSONMediaType)
+		w.Write([]byte(`{"type":"Person"}`))
+	}))
+	defer s.Close()
+	tests := []struct {
+		name    string
+		client  HttpClient
+		iri     string
+		checkFn func(err error) bool
+	}{
+		{
+			name:   "public https",
+			client: &http.Client{Transport: &contentTypeClient{contentType: activityJSONMediaType}},
+			iri:    "https://example.com/users/alice",
+			checkFn: func(err error) bool {
+				return err == nil
+			},
+		},
+		{
+			name:   "scheme",
+			client: &http.Client{Transport: &contentTypeClient{contentType: activityJSONMediaType}},
+			iri:    "http://example.com/users/alice",
+			checkFn: func(err error) bool {
+				_, ok := err.(*DisallowedSchemeError)
+				return ok
+			},
+		},
+		{
+			name:   "private address",
+			client: &http.Client{Transport: &contentTypeClient{contentType: activityJSONMediaType}},
+			iri:    "https://169.254.169.254/latest/meta-data",
+			checkFn: func(err error) bool {
+				_, ok := err.(*DisallowedAddressError)
+				return ok
+			},
+		},
+		{
+			name:   "redirect to private address",
+			client: &http.Client{Transport: redirectingRoundTripper{location: "https://169.254.169.254/latest/meta-data"}},
+			iri:    "https://example.com/users/alice",
+			checkFn: func(err error) bool {
+				_, ok := err.(*DisallowedAddressError)
+				return ok
+			},
+		},
+		{
+			name:   "http client",
+			client: &http.Client{},
+			iri:    s.URL,
+			checkFn: func(err error) bool {
+				_, ok := err.(*DisallowedSchemeError)
+				return ok
+			},
+		},
+		{
+			name:   "opted out",
+			client: WithoutFetchPolicy(&http.Client{}),
+			iri:    s.URL,
+			checkFn: func(err error) bool {
+				return err == nil
+			},
+		},
+	}
+	for _, test := range tests {
+		iri, err := url.Parse(test.iri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tp, err := NewHttpSigTransport(test.client, "app", "go-fed", fixedClock(time.Now()), nil, testKeyId, privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tp.Dereference(context.Background(), iri); !test.checkFn(err) {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+	}
+	// The redirects other clients follow cannot be checked.
+	if _, err := NewHttpSigTransport(&contentTypeClient{contentType: activityJSONMediaType}, "app", "go-fed", fixedClock(time.Now()), nil, testKeyId, privKey); err != ErrUnrestrictedHttpClient {
+		t.Fatalf("expected %v, got %v", ErrUnrestrictedHttpClient, err)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.20.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, test := range tests {
+		if actual := isPublicIP(net.ParseIP(test.ip)); actual != test.expected {
+			t.Fatalf("(%q): expected %v, got %v", test.ip, test.expected, actual)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/go-fed/activity/streams"
 	"net/http"
+	"net/url"
 )
 
 // HandlerFunc determines whether an incoming HTTP request is an ActivityStreams
@@ -45,12 +46,43 @@ type AuthenticateFunc func(c context.Context, w http.ResponseWriter, r *http.Req
 // before responding with them. Sets the appropriate HTTP status code for
 // Tombstone Activities as well.
 func NewActivityStreamsHandler(authFn AuthenticateFunc, db Database, clock Clock) HandlerFunc {
+	return newActivityStreamsHandler(nil, authFn, db, clock)
+}
+
+// NewAuthorizedFetchHandler creates a HandlerFunc like NewActivityStreamsHandler
+// that additionally requires requests to have a valid HTTP Signature, which is
+// verified before calling authFn.
+//
+// Requests that are unsigned, or whose signature does not verify, are answered
+// with an Unauthorized status. Otherwise the owner of the signing key is set in
+// the context passed to authFn and the Database, and is available with
+// RequesterFromContext. This permits refusing to serve data to blocked peers.
+func NewAuthorizedFetchHandler(v *HttpSigAuthenticator, authFn AuthenticateFunc, db Database, clock Clock) HandlerFunc {
+	return newActivityStreamsHandler(v, authFn, db, clock)
+}
+
+// newActivityStreamsHandler creates a HandlerFunc that verifies the HTTP
+// Signature on requests if the HttpSigAuthenticator is not nil.
+func newActivityStreamsHandler(v *HttpSigAuthenticator, authFn AuthenticateFunc, db Database, clock Clock) HandlerFunc {
 	return func(c context.Context, w http.ResponseWriter, r *http.Request) (isASRequest bool, err error) {
 		// Do nothing if it is not an ActivityPub GET request
 		if !isActivityPubGet(r) {
 			return
 		}
 		isASRequest = true
+		// Identify the requester, if required
+		if v != nil {
+			var requester *url.URL
+			requester, err = v.VerifyRequest(c, r)
+			if status := authenticationFailureStatus(err); status != 0 {
+				w.WriteHeader(status)
+				err = nil
+				return
+			} else if err != nil {
+				return
+			}
+			c = WithRequester(c, requester)
+		}
 		// Authenticate the request
 		var shouldReturn bool
 		if shouldReturn, err = authFn(c, w, r); err != nil {
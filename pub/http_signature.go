@@ -0,0 +1,590 @@
+package pub
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/go-fed/activity/streams/vocab"
+	"github.com/go-fed/httpsig"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// The JSON key for the public keys embedded in an actor.
+	publicKeyKey = "publicKey"
+	// The JSON key for the type of a value.
+	typePropertyKey = "type"
+	// The type name of a public key.
+	publicKeyTypeName = "PublicKey"
+	// The Host header, which servers receive as the request's Host.
+	hostHeader = "Host"
+	// The HTTP Signature header.
+	signatureHeader = "Signature"
+	// The HTTP Signature parameter listing the signed headers.
+	signedHeadersParameter = "headers"
+	// The number of public keys an HttpSigAuthenticator caches at most.
+	keyCacheCapacity = 1024
+	// How long an HttpSigAuthenticator caches a public key before fetching
+	// it again, so that revoked keys stop verifying.
+	keyCacheTTL = time.Hour
+	// How long an HttpSigAuthenticator waits before fetching a cached key
+	// that stopped verifying once more, so that bad signatures cannot make
+	// it fetch the key on every request.
+	keyRefetchInterval = time.Minute
+)
+
+// requiredSignedHeaders are the headers an HTTP Signature must cover, so that
+// it cannot be replayed against another inbox or at another time. POSTs must
+// also cover their Digest header, so that it cannot be replayed with another
+// body.
+var requiredSignedHeaders = []string{httpsig.RequestTarget, "host", "date"}
+
+// SignatureHeaderError indicates the HTTP Signature on a request is missing or
+// malformed.
+type SignatureHeaderError struct {
+	Err error
+}
+
+// Error returns a description of the malformed HTTP Signature.
+func (e *SignatureHeaderError) Error() string {
+	return fmt.Sprintf("missing or malformed http signature: %s", e.Err)
+}
+
+// KeyFetchError indicates the public key named by an HTTP Signature's keyId
+// could not be dereferenced.
+type KeyFetchError struct {
+	KeyId string
+	Err   error
+}
+
+// Error returns a description of the failed dereference.
+func (e *KeyFetchError) Error() string {
+	return fmt.Sprintf("cannot fetch public key %q: %s", e.KeyId, e.Err)
+}
+
+// KeyFormatError indicates the dereferenced public key document does not
+// contain a usable public key for the keyId.
+type KeyFormatError struct {
+	KeyId string
+	Err   error
+}
+
+// Error returns a description of the unusable public key.
+func (e *KeyFormatError) Error() string {
+	return fmt.Sprintf("cannot use public key %q: %s", e.KeyId, e.Err)
+}
+
+// SignatureVerificationError indicates the HTTP Signature did not verify
+// against the public key named by its keyId.
+type SignatureVerificationError struct {
+	KeyId string
+	Err   error
+}
+
+// Error returns a description of the failed verification.
+func (e *SignatureVerificationError) Error() string {
+	return fmt.Sprintf("http signature does not verify with key %q: %s", e.KeyId, e.Err)
+}
+
+// KeyOwnerMismatchError indicates the owner of the key that signed the request
+// is not the actor of the activity being delivered.
+//
+// Actor is nil if the activity has no actor.
+type KeyOwnerMismatchError struct {
+	Owner *url.URL
+	Actor *url.URL
+}
+
+// Error returns a description of the mismatched owner and actor.
+func (e *KeyOwnerMismatchError) Error() string {
+	if e.Actor == nil {
+		return fmt.Sprintf("key owner %q signed an activity without an actor", e.Owner)
+	}
+	return fmt.Sprintf("key owner %q does not match activity actor %q", e.Owner, e.Actor)
+}
+
+// HttpSigAuthenticator authenticates requests from federated peers by
+// verifying their HTTP Signature.
+//
+// Its AuthenticatePostInbox method satisfies the FederatingProtocol method of
+// the same name, so an application may delegate to it directly, and answers
+// requests that are not authentic itself. Its other methods instead return one
+// of SignatureHeaderError, KeyFetchError, KeyFormatError,
+// SignatureVerificationError, or KeyOwnerMismatchError for them.
+//
+// Public keys are cached once they verify a signature, and refetched when
+// they stop verifying so that peers are able to rotate their keys. A key is
+// refetched at most once a minute, bypassing any cache of the Transport that
+// is an Invalidator. Up to 1024 keys are cached for at most an hour, evicting
+// the least recently used first. It is safe for concurrent use.
+type HttpSigAuthenticator struct {
+	newTransport func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error)
+	algos        []httpsig.Algorithm
+	// canon, if set, permits authenticating forwarded activities by their
+	// Linked Data Signature.
+	canon Canonicalizer
+	// now determines when cached keys expire.
+	now func() time.Time
+	// capacity is the number of keys cached at most.
+	capacity int
+	// mu protects keys and lru.
+	mu sync.Mutex
+	// keys caches the public keys that have verified, by keyId.
+	keys map[string]*list.Element
+	// lru holds *cachedPublicKey, the most recently used at the front.
+	lru *list.List
+}
+
+// cachedPublicKey is a public key that has verified a signature, and its owner.
+type cachedPublicKey struct {
+	keyId   string
+	pubKey  crypto.PublicKey
+	owner   *url.URL
+	expires time.Time
+	// refetchAfter is when the key may be fetched again after it stopped
+	// verifying. It is protected by the HttpSigAuthenticator's mu.
+	refetchAfter time.Time
+}
+
+// NewHttpSigAuthenticator returns a new HttpSigAuthenticator.
+//
+// The newTransport function is used to dereference the public keys of peers,
+// and is typically the FederatingProtocol's NewTransport. It is called with
+// the URL of the request being authenticated.
+//
+// The algorithms are tried in order when verifying a signature. If none are
+// provided, RSA-SHA256 is used.
+func NewHttpSigAuthenticator(
+	newTransport func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error),
+	algos ...httpsig.Algorithm) *HttpSigAuthenticator {
+	return NewHttpSigAuthenticatorWithLDSignatures(newTransport, nil, algos...)
+}
+
+// NewHttpSigAuthenticatorWithLDSignatures returns a new HttpSigAuthenticator
+// that also accepts Linked Data Signatures canonicalized by canon.
+//
+// AuthenticatePostInbox then authenticates an activity by its embedded Linked
+// Data Signature when the HTTP Signature belongs to someone other than the
+// actor, as happens when an activity is forwarded. A nil canon accepts only
+// HTTP Signatures, as NewHttpSigAuthenticator does.
+func NewHttpSigAuthenticatorWithLDSignatures(
+	newTransport func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error),
+	canon Canonicalizer,
+	algos ...httpsig.Algorithm) *HttpSigAuthenticator {
+	if len(algos) == 0 {
+		algos = []httpsig.Algorithm{httpsig.RSA_SHA256}
+	}
+	return &HttpSigAuthenticator{
+		newTransport: newTransport,
+		algos:        algos,
+		canon:        canon,
+		now:          time.Now,
+		capacity:     keyCacheCapacity,
+		keys:         make(map[string]*list.Element),
+		lru:          list.New(),
+	}
+}
+
+// AuthenticatePostInbox verifies the HTTP Signature on a POST to an inbox and
+// ensures the owner of the signing key is the actor of the delivered
+// activity.
+//
+// If it was created with a Canonicalizer and the owner is not the actor, the
+// activity is instead authenticated by its embedded Linked Data Signature.
+//
+// The owner of the key, or the creator of the Linked Data Signature, is
+// recorded on the request with SetRequester. A request that cannot be
+// authenticated is answered with an Unauthorized status, or a Forbidden status
+// if the key's owner is not the actor, and shouldReturn is true. Other errors
+// are returned.
+//
+// The request body is restored after being read, so it is safe to continue
+// processing the request afterwards.
+func (h *HttpSigAuthenticator) AuthenticatePostInbox(c context.Context, w http.ResponseWriter, r *http.Request) (shouldReturn bool, err error) {
+	sender, err := h.authenticatePostInbox(c, r)
+	if status := authenticationFailureStatus(err); status != 0 {
+		w.WriteHeader(status)
+		return true, nil
+	} else if err != nil {
+		return
+	}
+	SetRequester(r, sender)
+	return
+}
+
+// authenticatePostInbox authenticates the activity POSTed to an inbox by the
+// request's HTTP Signature, or else by its Linked Data Signature, returning
+// the actor whose signature it is.
+func (h *HttpSigAuthenticator) authenticatePostInbox(c context.Context, r *http.Request) (sender *url.URL, err error) {
+	owner, err := h.VerifyRequest(c, r)
+	if err != nil {
+		return
+	}
+	raw, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(raw))
+	var m map[string]interface{}
+	if err = json.Unmarshal(raw, &m); err != nil {
+		return
+	}
+	t, err := toType(c, m)
+	if err != nil {
+		return
+	}
+	activity, ok := t.(Activity)
+	if !ok {
+		err = fmt.Errorf("activity streams value is not an Activity: %T", t)
+		return
+	}
+	sender = owner
+	err = mustHaveKeyOwnerMatchActors(owner, activity)
+	if _, ok := err.(*KeyOwnerMismatchError); ok && h.canon != nil {
+		// The activity may have been forwarded, in which case only its
+		// Linked Data Signature belongs to the actor.
+		if sender, err = h.VerifyLDSignature(c, r.URL, m); err != nil {
+			return
+		}
+		err = mustHaveKeyOwnerMatchActors(sender, activity)
+	}
+	if err != nil {
+		sender = nil
+	}
+	return
+}
+
+// VerifyRequest verifies the HTTP Signature on the request, returning the IRI
+// of the owner of the key that signed it.
+//
+// The signature must cover the request target, and the Host and Date headers,
+// as well as the Digest header of a POST. Otherwise a SignatureHeaderError is
+// returned.
+func (h *HttpSigAuthenticator) VerifyRequest(c context.Context, r *http.Request) (owner *url.URL, err error) {
+	// Servers receive the Host header as the request's Host, while the
+	// verifier only reads the request's headers.
+	if len(r.Header.Get(hostHeader)) == 0 && len(r.Host) > 0 {
+		r.Header.Set(hostHeader, r.Host)
+	}
+	v, err := httpsig.NewVerifier(r)
+	if err != nil {
+		err = &SignatureHeaderError{Err: err}
+		return
+	}
+	if err = mustHaveRequiredSignedHeaders(r); err != nil {
+		return
+	}
+	keyId := v.KeyId()
+	return h.verifyWithKey(c, r.URL, keyId, func(pubKey crypto.PublicKey) (err error) {
+		for _, algo := range h.algos {
+			if err = v.Verify(pubKey, algo); err == nil {
+				return
+			}
+		}
+		return &SignatureVerificationError{KeyId: keyId, Err: err}
+	})
+}
+
+// signedHeaders returns the lowercased names of the headers covered by the HTTP
+// Signature in the Signature header, or else the Authorization header. As in the httpsig
+// library, a signature that does not list them only covers the Date header.
+func signedHeaders(h http.Header) []string {
+	s := h.Get(signatureHeader)
+	if len(s) == 0 {
+		s = strings.TrimPrefix(h.Get(authorizationHeader), signatureHeader+" ")
+	}
+	for _, p := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) == 2 && kv[0] == signedHeadersParameter {
+			return strings.Fields(strings.ToLower(strings.Trim(kv[1], "\"")))
+		}
+	}
+	return []string{"date"}
+}
+
+// isSignedHeader determines whether the HTTP Signature covers the header.
+func isSignedHeader(h http.Header, name string) bool {
+	name = strings.ToLower(name)
+	for _, signed := range signedHeaders(h) {
+		if signed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mustHaveRequiredSignedHeaders ensures the HTTP Signature on the request
+// covers the requiredSignedHeaders, and the Digest header of a POST.
+func mustHaveRequiredSignedHeaders(r *http.Request) error {
+	required := requiredSignedHeaders
+	if r.Method == http.MethodPost {
+		required = append(required[:len(required):len(required)], digestHeader)
+	}
+	for _, name := range required {
+		if !isSignedHeader(r.Header, name) {
+			return &SignatureHeaderError{Err: fmt.Errorf("http signature does not cover %q", strings.ToLower(name))}
+		}
+	}
+	return nil
+}
+
+// authenticationFailureStatus returns the status to answer a request with when
+// the error returned by verifying its signatures means the request cannot be
+// authenticated, rather than that verifying it failed for another reason. It
+// is zero otherwise.
+func authenticationFailureStatus(err error) int {
+	switch err.(type) {
+	case *SignatureHeaderError, *KeyFetchError, *KeyFormatError, *SignatureVerificationError, *LDSignatureError:
+		return http.StatusUnauthorized
+	case *KeyOwnerMismatchError:
+		return http.StatusForbidden
+	default:
+		return 0
+	}
+}
+
+// verifyWithKey obtains the public key for the keyId and calls verify with it,
+// returning the owner of the key if verify succeeds.
+//
+// Keys that verify are cached. If a cached key fails to verify, the owner may
+// have rotated it, so it is fetched once more, bypassing the Transport's
+// cache, and the new key replaces it. This happens at most once per
+// keyRefetchInterval for each key.
+func (h *HttpSigAuthenticator) verifyWithKey(c context.Context, boxIRI *url.URL, keyId string, verify func(pubKey crypto.PublicKey) error) (owner *url.URL, err error) {
+	cached, isCached := h.cachedKey(keyId)
+	if isCached {
+		if err = verify(cached.pubKey); err == nil {
+			owner = cached.owner
+			return
+		} else if !h.mayRefetch(cached) {
+			return
+		}
+	}
+	pubKey, owner, err := h.fetchPublicKey(c, boxIRI, keyId, isCached)
+	if err != nil {
+		return
+	}
+	if err = verify(pubKey); err != nil {
+		owner = nil
+		return
+	}
+	h.cacheKey(&cachedPublicKey{
+		keyId:   keyId,
+		pubKey:  pubKey,
+		owner:   owner,
+		expires: h.now().Add(keyCacheTTL),
+	})
+	return
+}
+
+// cachedKey returns the cached key for the keyId if it has not expired,
+// marking it as recently used.
+func (h *HttpSigAuthenticator) cachedKey(keyId string) (*cachedPublicKey, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e, ok := h.keys[keyId]
+	if !ok {
+		return nil, false
+	}
+	cached := e.Value.(*cachedPublicKey)
+	if !h.now().Before(cached.expires) {
+		delete(h.keys, keyId)
+		h.lru.Remove(e)
+		return nil, false
+	}
+	h.lru.MoveToFront(e)
+	return cached, true
+}
+
+// mayRefetch determines whether the cached key that stopped verifying may be
+// fetched again now, and if so prevents it from being fetched again until the
+// keyRefetchInterval has passed.
+func (h *HttpSigAuthenticator) mayRefetch(cached *cachedPublicKey) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	if now.Before(cached.refetchAfter) {
+		return false
+	}
+	cached.refetchAfter = now.Add(keyRefetchInterval)
+	return true
+}
+
+// cacheKey caches the key, replacing any for the same keyId and evicting the
+// least recently used keys as needed.
+func (h *HttpSigAuthenticator) cacheKey(cached *cachedPublicKey) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if e, ok := h.keys[cached.keyId]; ok {
+		e.Value = cached
+		h.lru.MoveToFront(e)
+		return
+	}
+	for h.lru.Len() > 0 && h.lru.Len() >= h.capacity {
+		oldest := h.lru.Back()
+		delete(h.keys, oldest.Value.(*cachedPublicKey).keyId)
+		h.lru.Remove(oldest)
+	}
+	h.keys[cached.keyId] = h.lru.PushFront(cached)
+}
+
+// fetchPublicKey dereferences the keyId and obtains the public key and its
+// owner.
+//
+// When refetching a key that stopped verifying, any data the Transport cached
+// for the keyId is invalidated first, so that a rotated key is obtained.
+func (h *HttpSigAuthenticator) fetchPublicKey(c context.Context, boxIRI *url.URL, keyId string, refetch bool) (pubKey crypto.PublicKey, owner *url.URL, err error) {
+	keyIRI, err := url.Parse(keyId)
+	if err != nil {
+		err = &KeyFetchError{KeyId: keyId, Err: err}
+		return
+	}
+	t, err := h.newTransport(c, boxIRI, goFedUserAgent())
+	if err != nil {
+		return
+	}
+	if i, ok := t.(Invalidator); ok && refetch {
+		if err = i.Invalidate(c, keyIRI); err != nil {
+			return
+		}
+	}
+	b, err := t.Dereference(c, keyIRI)
+	if err != nil {
+		err = &KeyFetchError{KeyId: keyId, Err: err}
+		return
+	}
+	pubKey, owner, err = parsePublicKeyDocument(c, keyIRI, b)
+	if err != nil {
+		err = &KeyFormatError{KeyId: keyId, Err: err}
+	}
+	return
+}
+
+// parsePublicKeyDocument obtains the public key and its owner from a
+// dereferenced keyId. The document is either the key itself, or an actor with
+// the key in its 'publicKey' property.
+//
+// The owner must be on the same host as the key.
+func parsePublicKeyDocument(c context.Context, keyIRI *url.URL, b []byte) (pubKey crypto.PublicKey, owner *url.URL, err error) {
+	var m map[string]interface{}
+	if err = json.Unmarshal(b, &m); err != nil {
+		return
+	}
+	addPublicKeyTypes(m)
+	var t vocab.Type
+	t, err = toType(c, m)
+	if err != nil {
+		return
+	}
+	var key vocab.W3IDSecurityV1PublicKey
+	if pk, ok := t.(vocab.W3IDSecurityV1PublicKey); ok {
+		var id *url.URL
+		if id, err = GetId(pk); err != nil {
+			return
+		} else if id.String() != keyIRI.String() {
+			err = fmt.Errorf("document id %q is not the key id", id)
+			return
+		}
+		key = pk
+	} else if pker, ok := t.(publicKeyer); ok {
+		if key, err = findPublicKey(pker.GetW3IDSecurityV1PublicKey(), keyIRI); err != nil {
+			return
+		}
+	} else {
+		err = fmt.Errorf("%s has no public key", t.GetName())
+		return
+	}
+	ownerProp := key.GetW3IDSecurityV1Owner()
+	if ownerProp == nil || !ownerProp.IsXMLSchemaAnyURI() {
+		err = fmt.Errorf("public key has no owner")
+		return
+	}
+	owner = ownerProp.Get()
+	if owner.Host != keyIRI.Host {
+		err = fmt.Errorf("owner %q is not on the same host as the key", owner)
+		return
+	}
+	pemProp := key.GetW3IDSecurityV1PublicKeyPem()
+	if pemProp == nil || !pemProp.IsXMLSchemaString() {
+		err = fmt.Errorf("public key has no publicKeyPem")
+		return
+	}
+	pubKey, err = parsePublicKeyPem(pemProp.Get())
+	return
+}
+
+// addPublicKeyTypes sets the 'type' of public keys embedded in an actor that
+// omit it, as many implementations do, so they resolve as PublicKey values.
+func addPublicKeyTypes(m map[string]interface{}) {
+	pk, ok := m[publicKeyKey]
+	if !ok {
+		return
+	}
+	keys, ok := pk.([]interface{})
+	if !ok {
+		keys = []interface{}{pk}
+	}
+	for _, k := range keys {
+		if km, ok := k.(map[string]interface{}); ok {
+			if _, ok := km[typePropertyKey]; !ok {
+				km[typePropertyKey] = publicKeyTypeName
+			}
+		}
+	}
+}
+
+// findPublicKey returns the embedded public key with the given id.
+func findPublicKey(keys vocab.W3IDSecurityV1PublicKeyProperty, keyIRI *url.URL) (vocab.W3IDSecurityV1PublicKey, error) {
+	if keys != nil {
+		for iter := keys.Begin(); iter != keys.End(); iter = iter.Next() {
+			if !iter.IsW3IDSecurityV1PublicKey() {
+				continue
+			}
+			if id, err := GetId(iter.Get()); err == nil && id.String() == keyIRI.String() {
+				return iter.Get(), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("actor has no public key with id %q", keyIRI)
+}
+
+// parsePublicKeyPem decodes a PEM encoded PKIX or PKCS1 public key.
+func parsePublicKeyPem(s string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, fmt.Errorf("publicKeyPem is not PEM encoded")
+	}
+	if pubKey, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return pubKey, nil
+	}
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
+
+// mustHaveKeyOwnerMatchActors ensures that every actor on the activity is the
+// owner of the key that signed the request.
+func mustHaveKeyOwnerMatchActors(owner *url.URL, activity Activity) error {
+	actors := activity.GetActivityStreamsActor()
+	if actors == nil || actors.Len() == 0 {
+		return &KeyOwnerMismatchError{Owner: owner}
+	}
+	for iter := actors.Begin(); iter != actors.End(); iter = iter.Next() {
+		id, err := ToId(iter)
+		if err != nil {
+			return err
+		}
+		if id.String() != owner.String() {
+			return &KeyOwnerMismatchError{Owner: owner, Actor: id}
+		}
+	}
+	return nil
+}
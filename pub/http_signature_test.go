@@ -0,0 +1,408 @@
+package pub
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/go-fed/httpsig"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const (
+	testKeyOwner = "https://example.com/users/alice"
+	testKeyId    = "https://example.com/users/alice#main-key"
+)
+
+// fixedTransport dereferences every IRI to the same document.
+type fixedTransport struct {
+	Transport
+	doc []byte
+}
+
+func (f fixedTransport) Dereference(c context.Context, iri *url.URL) ([]byte, error) {
+	return f.doc, nil
+}
+
+func newTestKeyPair(t *testing.T) (*rsa.PrivateKey, string) {
+	privKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&privKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privKey, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// signTestRequest signs the request with the headers an HttpSigAuthenticator
+// requires, adding the Digest of the body if it is a POST.
+func signTestRequest(t *testing.T, r *http.Request, body []byte, privKey crypto.PrivateKey, keyId string) {
+	headers := requiredSignedHeaders
+	if r.Method == http.MethodPost {
+		headers = append(headers[:len(headers):len(headers)], "digest")
+	}
+	signTestRequestHeaders(t, r, body, privKey, keyId, headers)
+}
+
+// signTestRequestHeaders signs the given headers of the request, adding the
+// Digest of the body if it is a POST.
+func signTestRequestHeaders(t *testing.T, r *http.Request, body []byte, privKey crypto.PrivateKey, keyId string, headers []string) {
+	r.Header.Set(hostHeader, r.Host)
+	if r.Method == http.MethodPost {
+		r.Header.Set(digestHeader, digest(body))
+	}
+	signer, _, err := httpsig.NewSigner([]httpsig.Algorithm{httpsig.RSA_SHA256}, headers, httpsig.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.SignRequest(privKey, keyId, r); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHttpSigAuthenticatorAuthenticatePostInbox(t *testing.T) {
+	privKey, pubPem := newTestKeyPair(t)
+	actorDoc := []byte(fmt.Sprintf(`{"@context":["https://www.w3.org/ns/activitystreams","https://w3id.org/security/v1"],"id":%q,"type":"Person","publicKey":{"id":%q,"owner":%q,"publicKeyPem":%q}}`,
+		testKeyOwner, testKeyId, testKeyOwner, pubPem))
+	postHeaders := append(requiredSignedHeaders[:len(requiredSignedHeaders):len(requiredSignedHeaders)], "digest")
+	tests := []struct {
+		name    string
+		actor   string
+		sign    bool
+		headers []string
+		code    int
+	}{
+		{
+			name:    "authentic",
+			actor:   testKeyOwner,
+			sign:    true,
+			headers: postHeaders,
+			code:    http.StatusOK,
+		},
+		{
+			name:    "date only",
+			actor:   testKeyOwner,
+			sign:    true,
+			headers: []string{"date"},
+			code:    http.StatusUnauthorized,
+		},
+		{
+			name:    "digest not signed",
+			actor:   testKeyOwner,
+			sign:    true,
+			headers: requiredSignedHeaders,
+			code:    http.StatusUnauthorized,
+		},
+		{
+			name:  "unsigned",
+			actor: testKeyOwner,
+			code:  http.StatusUnauthorized,
+		},
+		{
+			name:    "different actor",
+			actor:   "https://example.com/users/mallory",
+			sign:    true,
+			headers: postHeaders,
+			code:    http.StatusForbidden,
+		},
+	}
+	for _, test := range tests {
+		body := []byte(fmt.Sprintf(`{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/activity/1","type":"Like","actor":%q,"object":"https://example.net/note/1"}`, test.actor))
+		r := httptest.NewRequest("POST", "https://example.net/users/bob/inbox", bytes.NewReader(body))
+		r.Header.Set(dateHeader, "Mon, 02 Jan 2006 15:04:05 GMT")
+		if test.sign {
+			signTestRequestHeaders(t, r, body, privKey, testKeyId, test.headers)
+		}
+		a := NewHttpSigAuthenticator(func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+			return fixedTransport{doc: actorDoc}, nil
+		})
+		w := httptest.NewRecorder()
+		shouldReturn, err := a.AuthenticatePostInbox(context.Background(), w, r)
+		if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if shouldReturn != (test.code != http.StatusOK) {
+			t.Fatalf("(%q): expected shouldReturn %v, got %v", test.name, test.code != http.StatusOK, shouldReturn)
+		} else if w.Code != test.code {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.code, w.Code)
+		}
+		var expected string
+		if test.code == http.StatusOK {
+			expected = testKeyOwner
+		}
+		var actual string
+		if requester := RequesterFromContext(r.Context()); requester != nil {
+			actual = requester.String()
+		}
+		if actual != expected {
+			t.Fatalf("(%q): expected requester %q, got %q", test.name, expected, actual)
+		}
+	}
+}
+
+func TestParsePublicKeyDocument(t *testing.T) {
+	_, pubPem := newTestKeyPair(t)
+	keyIRI, err := url.Parse(testKeyId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr bool
+	}{
+		{
+			name: "actor with untyped key",
+			doc: fmt.Sprintf(`{"@context":["https://www.w3.org/ns/activitystreams","https://w3id.org/security/v1"],"id":%q,"type":"Person","publicKey":{"id":%q,"owner":%q,"publicKeyPem":%q}}`,
+				testKeyOwner, testKeyId, testKeyOwner, pubPem),
+		},
+		{
+			name: "actor with several keys",
+			doc: fmt.Sprintf(`{"@context":["https://www.w3.org/ns/activitystreams","https://w3id.org/security/v1"],"id":%q,"type":"Service","publicKey":[{"id":"https://example.com/users/alice#other-key","type":"PublicKey","owner":%q,"publicKeyPem":"bogus"},{"id":%q,"type":"PublicKey","owner":%q,"publicKeyPem":%q}]}`,
+				testKeyOwner, testKeyOwner, testKeyId, testKeyOwner, pubPem),
+		},
+		{
+			name: "key document",
+			doc: fmt.Sprintf(`{"@context":"https://w3id.org/security/v1","id":%q,"type":"PublicKey","owner":%q,"publicKeyPem":%q}`,
+				testKeyId, testKeyOwner, pubPem),
+		},
+		{
+			name: "actor without the key",
+			doc: fmt.Sprintf(`{"@context":["https://www.w3.org/ns/activitystreams","https://w3id.org/security/v1"],"id":%q,"type":"Person","publicKey":{"id":"https://example.com/users/alice#other-key","owner":%q,"publicKeyPem":%q}}`,
+				testKeyOwner, testKeyOwner, pubPem),
+			wantErr: true,
+		},
+		{
+			name: "owner on another host",
+			doc: fmt.Sprintf(`{"@context":"https://w3id.org/security/v1","id":%q,"type":"PublicKey","owner":"https://example.net/users/mallory","publicKeyPem":%q}`,
+				testKeyId, pubPem),
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		pubKey, owner, err := parsePublicKeyDocument(context.Background(), keyIRI, []byte(test.doc))
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("(%q): expected error, got none", test.name)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if pubKey == nil {
+			t.Fatalf("(%q): expected a public key, got nil", test.name)
+		} else if owner.String() != testKeyOwner {
+			t.Fatalf("(%q): expected %v, got %v", test.name, testKeyOwner, owner)
+		}
+	}
+}
+
+// countingTransport dereferences every IRI to its current document, counting
+// the dereferences.
+type countingTransport struct {
+	Transport
+	doc   *[]byte
+	count *int
+}
+
+func (f countingTransport) Dereference(c context.Context, iri *url.URL) ([]byte, error) {
+	*f.count++
+	return *f.doc, nil
+}
+
+func TestHttpSigAuthenticatorKeyRotation(t *testing.T) {
+	oldKey, oldPem := newTestKeyPair(t)
+	newKey, newPem := newTestKeyPair(t)
+	doc := testActorDoc(testKeyOwner, oldPem)
+	var count int
+	a := NewHttpSigAuthenticator(func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+		return countingTransport{doc: &doc, count: &count}, nil
+	})
+	tests := []struct {
+		name     string
+		key      *rsa.PrivateKey
+		doc      []byte
+		expected int
+	}{
+		{
+			name:     "first request fetches",
+			key:      oldKey,
+			doc:      testActorDoc(testKeyOwner, oldPem),
+			expected: 1,
+		},
+		{
+			name:     "second request is cached",
+			key:      oldKey,
+			doc:      testActorDoc(testKeyOwner, oldPem),
+			expected: 1,
+		},
+		{
+			name:     "rotated key is refetched",
+			key:      newKey,
+			doc:      testActorDoc(testKeyOwner, newPem),
+			expected: 2,
+		},
+		{
+			name:     "rotated key is cached",
+			key:      newKey,
+			doc:      testActorDoc(testKeyOwner, newPem),
+			expected: 2,
+		},
+	}
+	for _, test := range tests {
+		doc = test.doc
+		r := httptest.NewRequest("POST", "https://example.net/users/bob/inbox", nil)
+		r.Header.Set(dateHeader, "Mon, 02 Jan 2006 15:04:05 GMT")
+		signTestRequest(t, r, nil, test.key, testKeyId)
+		if _, err := a.VerifyRequest(context.Background(), r); err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if count != test.expected {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expected, count)
+		}
+	}
+}
+
+func TestHttpSigAuthenticatorKeyRotationWithCachingTransport(t *testing.T) {
+	oldKey, oldPem := newTestKeyPair(t)
+	newKey, newPem := newTestKeyPair(t)
+	junkKey, _ := newTestKeyPair(t)
+	doc := testActorDoc(testKeyOwner, oldPem)
+	var count int
+	clock := &settableClock{now: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)}
+	cache := NewMemoryDereferenceCache(10)
+	a := NewHttpSigAuthenticator(func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+		return NewCachingTransport(countingTransport{doc: &doc, count: &count}, cache, clock, time.Hour, 0), nil
+	})
+	a.now = clock.Now
+	tests := []struct {
+		name      string
+		key       *rsa.PrivateKey
+		doc       []byte
+		advance   time.Duration
+		expectErr bool
+		expected  int
+	}{
+		{
+			name:     "first request fetches",
+			key:      oldKey,
+			doc:      testActorDoc(testKeyOwner, oldPem),
+			expected: 1,
+		},
+		{
+			name:     "rotated key bypasses the cached actor",
+			key:      newKey,
+			doc:      testActorDoc(testKeyOwner, newPem),
+			expected: 2,
+		},
+		{
+			name:      "bad signature refetches",
+			key:       junkKey,
+			doc:       testActorDoc(testKeyOwner, newPem),
+			expectErr: true,
+			expected:  3,
+		},
+		{
+			name:      "bad signature is not refetched again",
+			key:       junkKey,
+			doc:       testActorDoc(testKeyOwner, newPem),
+			expectErr: true,
+			expected:  3,
+		},
+		{
+			name:     "rotated key is cached",
+			key:      newKey,
+			doc:      testActorDoc(testKeyOwner, newPem),
+			expected: 3,
+		},
+		{
+			name:      "bad signature refetches after the interval",
+			key:       junkKey,
+			doc:       testActorDoc(testKeyOwner, newPem),
+			advance:   keyRefetchInterval,
+			expectErr: true,
+			expected:  4,
+		},
+	}
+	for _, test := range tests {
+		doc = test.doc
+		clock.now = clock.now.Add(test.advance)
+		r := httptest.NewRequest("POST", "https://example.net/users/bob/inbox", nil)
+		r.Header.Set(dateHeader, "Mon, 02 Jan 2006 15:04:05 GMT")
+		signTestRequest(t, r, nil, test.key, testKeyId)
+		_, err := a.VerifyRequest(context.Background(), r)
+		if test.expectErr && err == nil {
+			t.Fatalf("(%q): expected error", test.name)
+		} else if !test.expectErr && err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if count != test.expected {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expected, count)
+		}
+	}
+}
+
+func TestHttpSigAuthenticatorKeyCacheBounds(t *testing.T) {
+	privKey, pubPem := newTestKeyPair(t)
+	const otherKeyOwner = "https://example.com/users/carol"
+	var doc []byte
+	var count int
+	a := NewHttpSigAuthenticator(func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+		return countingTransport{doc: &doc, count: &count}, nil
+	})
+	a.capacity = 1
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	a.now = func() time.Time { return now }
+	tests := []struct {
+		name     string
+		owner    string
+		advance  time.Duration
+		expected int
+	}{
+		{
+			name:     "first request fetches",
+			owner:    testKeyOwner,
+			expected: 1,
+		},
+		{
+			name:     "second request is cached",
+			owner:    testKeyOwner,
+			advance:  keyCacheTTL - time.Second,
+			expected: 1,
+		},
+		{
+			name:     "expired key is refetched",
+			owner:    testKeyOwner,
+			advance:  time.Second,
+			expected: 2,
+		},
+		{
+			name:     "other key evicts the least recently used",
+			owner:    otherKeyOwner,
+			expected: 3,
+		},
+		{
+			name:     "evicted key is refetched",
+			owner:    testKeyOwner,
+			expected: 4,
+		},
+	}
+	for _, test := range tests {
+		now = now.Add(test.advance)
+		doc = testActorDoc(test.owner, pubPem)
+		r := httptest.NewRequest("POST", "https://example.net/users/bob/inbox", nil)
+		r.Header.Set(dateHeader, "Mon, 02 Jan 2006 15:04:05 GMT")
+		signTestRequest(t, r, nil, privKey, test.owner+"#main-key")
+		if _, err := a.VerifyRequest(context.Background(), r); err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if count != test.expected {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expected, count)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package pub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// InboxPersister saves the activities accepted by an InboxQueue until they are
+// processed, so that those still queued when the application stops are not
+// lost. Once restarted, the application resumes them with InboxQueue.Resume.
+type InboxPersister interface {
+	// Received saves the raw activity POSTed for the inboxes, along with
+	// the requester verified as its sender. It must return a unique id for
+	// it.
+	Received(c context.Context, raw []byte, inboxes []*url.URL, requester *url.URL) (id string, err error)
+	// Done indicates the activity no longer needs to be processed, either
+	// because it has been, or because the queue was full and the peer was
+	// asked to send it again later.
+	//
+	// It is not called for an activity that failed to be processed for
+	// one of its inboxes, unless it can never succeed, such as one without
+	// a required object. Such an activity remains pending, and is retried
+	// for all of its inboxes by ResumePending.
+	Done(c context.Context, id string) error
+	// Pending returns the activities saved by Received that are not yet
+	// Done.
+	Pending(c context.Context) ([]PendingInboxActivity, error)
+}
+
+// PendingInboxActivity is an activity saved by an InboxPersister that has not
+// finished being processed.
+type PendingInboxActivity struct {
+	Id        string
+	Raw       []byte
+	Inboxes   []*url.URL
+	Requester *url.URL
+}
+
+// MemoryInboxPersister is an InboxPersister that keeps the pending activities
+// in memory only. They are lost if the application stops before they are
+// processed, so applications that cannot afford to lose activities accepted
+// from peers must implement InboxPersister with durable storage instead.
+type MemoryInboxPersister struct {
+	mu      sync.Mutex
+	n       uint64
+	pending map[string]PendingInboxActivity
+}
+
+var _ InboxPersister = &MemoryInboxPersister{}
+
+// NewMemoryInboxPersister creates an empty MemoryInboxPersister.
+func NewMemoryInboxPersister() *MemoryInboxPersister {
+	return &MemoryInboxPersister{
+		pending: make(map[string]PendingInboxActivity),
+	}
+}
+
+// Received saves the activity until it is Done.
+func (m *MemoryInboxPersister) Received(c context.Context, raw []byte, inboxes []*url.URL, requester *url.URL) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.n++
+	id := strconv.FormatUint(m.n, 10)
+	m.pending[id] = PendingInboxActivity{
+		Id:        id,
+		Raw:       raw,
+		Inboxes:   inboxes,
+		Requester: requester,
+	}
+	return id, nil
+}
+
+// Done forgets the activity.
+func (m *MemoryInboxPersister) Done(c context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, id)
+	return nil
+}
+
+// Pending returns the activities not yet Done, in the order received.
+func (m *MemoryInboxPersister) Pending(c context.Context) ([]PendingInboxActivity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := make([]PendingInboxActivity, 0, len(m.pending))
+	for _, a := range m.pending {
+		p = append(p, a)
+	}
+	sort.Slice(p, func(i, j int) bool {
+		a, _ := strconv.ParseUint(p[i].Id, 10, 64)
+		b, _ := strconv.ParseUint(p[j].Id, 10, 64)
+		return a < b
+	})
+	return p, nil
+}
+
+// InboxQueue processes the activities POSTed to inboxes in the background, for
+// an Actor created with the WithAsyncInbox option. Each Actor needs its own
+// InboxQueue.
+//
+// The request is answered as soon as the activity is authenticated,
+// authorized, and queued, instead of once all of its side effects have been
+// applied. Any error processing it can then no longer be returned to the peer,
+// and is instead passed to the queue's error handler.
+type InboxQueue struct {
+	workers   int
+	persister InboxPersister
+	onError   func(c context.Context, inboxIRI *url.URL, activity Activity, err error)
+	// delegate is set by WithAsyncInbox, for the only Actor using the
+	// queue.
+	delegate DelegateActor
+	jobs     chan inboxJob
+	wg       sync.WaitGroup
+	mu       sync.RWMutex // Limits concurrent access to stopped and jobs
+	stopped  bool
+	started  bool
+}
+
+// inboxJob is an activity waiting in an InboxQueue.
+type inboxJob struct {
+	c        context.Context
+	id       string
+	inboxes  []*url.URL
+	activity Activity
+}
+
+// NewInboxQueue creates an InboxQueue that processes up to workers activities
+// at once, with up to size more waiting. When the queue is full, further
+// requests are answered with a Service Unavailable status, so that the peer
+// sends them again later.
+//
+// The persister is required, since the peer is told an activity was accepted
+// before it is processed, and an error is returned without one. The activities it still holds when the application
+// starts again are queued with ResumePending. The onError function is called
+// with any error, or recovered panic, from processing an activity for an
+// inbox. It is optional, but without it such errors go unnoticed.
+func NewInboxQueue(workers, size int, persister InboxPersister, onError func(c context.Context, inboxIRI *url.URL, activity Activity, err error)) (*InboxQueue, error) {
+	if persister == nil {
+		return nil, errors.New("inbox queue requires an InboxPersister")
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &InboxQueue{
+		workers:   workers,
+		persister: persister,
+		onError:   onError,
+		jobs:      make(chan inboxJob, size),
+	}, nil
+}
+
+// start starts the workers processing the queue for the delegate. It returns
+// ErrInboxQueueInUse if the queue is already used by another Actor, whose
+// delegate would otherwise process this one's activities.
+func (q *InboxQueue) start(delegate DelegateActor) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started {
+		return ErrInboxQueueInUse
+	}
+	q.started = true
+	q.delegate = delegate
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return nil
+}
+
+// Stop stops accepting activities and waits for those already queued to be
+// processed.
+func (q *InboxQueue) Stop() {
+	q.mu.Lock()
+	if !q.stopped {
+		q.stopped = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+// ResumePending queues every activity that the persister holds from a previous
+// InboxQueue that did not finish processing it, or failed to process it for
+// one of its inboxes, and should be called when the application starts. It
+// returns ErrInboxQueueFull if the queue runs out of room, in which case the
+// activities not yet queued remain persisted.
+func (q *InboxQueue) ResumePending(c context.Context) error {
+	pending, err := q.persister.Pending(c)
+	if err != nil {
+		return err
+	}
+	for _, p := range pending {
+		if err := q.Resume(c, p.Id, p.Raw, p.Inboxes, p.Requester); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resume queues an activity that a previous InboxQueue persisted but did not
+// finish processing, sent by the requester it persisted. It returns
+// ErrInboxQueueFull if the queue has no room, in which case the activity
+// remains persisted.
+func (q *InboxQueue) Resume(c context.Context, id string, raw []byte, inboxes []*url.URL, requester *url.URL) error {
+	activity, err := toActivity(c, raw)
+	if err != nil {
+		return err
+	}
+	if requester != nil {
+		c = WithRequester(c, requester)
+	}
+	return q.enqueue(inboxJob{
+		c:        c,
+		id:       id,
+		inboxes:  inboxes,
+		activity: activity,
+	})
+}
+
+// accept persists and queues an activity POSTed to the inboxes.
+func (q *InboxQueue) accept(c context.Context, raw []byte, inboxes []*url.URL, activity Activity) error {
+	j := inboxJob{
+		c:        c,
+		inboxes:  inboxes,
+		activity: activity,
+	}
+	var err error
+	if j.id, err = q.persister.Received(c, raw, inboxes, RequesterFromContext(c)); err != nil {
+		return err
+	}
+	err = q.enqueue(j)
+	if err == ErrInboxQueueFull {
+		if doneErr := q.persister.Done(c, j.id); doneErr != nil {
+			return doneErr
+		}
+	}
+	return err
+}
+
+// enqueue adds the job to the queue without waiting for room. The job's
+// context is detached from the request, which is answered before the job is
+// processed.
+func (q *InboxQueue) enqueue(j inboxJob) error {
+	j.c = detachedContext{j.c}
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.stopped {
+		return ErrInboxQueueFull
+	}
+	select {
+	case q.jobs <- j:
+		return nil
+	default:
+		return ErrInboxQueueFull
+	}
+}
+
+// work processes queued activities until the queue is stopped.
+//
+// An activity that failed to be processed for an inbox, and may succeed if
+// retried, is left pending in the persister.
+func (q *InboxQueue) work() {
+	defer q.wg.Done()
+	for j := range q.jobs {
+		done := true
+		for _, inboxIRI := range j.inboxes {
+			err := q.process(j.c, inboxIRI, j.activity)
+			if err == nil {
+				continue
+			} else if q.onError != nil {
+				q.onError(j.c, inboxIRI, j.activity, err)
+			}
+			if isRetryableInboxError(err) {
+				done = false
+			}
+		}
+		if !done {
+			continue
+		}
+		if err := q.persister.Done(j.c, j.id); err != nil && q.onError != nil {
+			q.onError(j.c, nil, j.activity, err)
+		}
+	}
+}
+
+// inboxPanicError is a panic recovered while processing an activity.
+type inboxPanicError struct {
+	inboxIRI *url.URL
+	v        interface{}
+}
+
+// Error returns a description of the panic.
+func (e *inboxPanicError) Error() string {
+	return fmt.Sprintf("panic processing activity for inbox %s: %v", e.inboxIRI, e.v)
+}
+
+// isRetryableInboxError determines whether processing an activity may succeed
+// if retried. Activities missing a required property, or that panicked, are
+// expected to fail again, as are those whose sender was not verified.
+func isRetryableInboxError(err error) bool {
+	if _, ok := err.(*inboxPanicError); ok {
+		return false
+	}
+	return err != ErrObjectRequired && err != ErrTargetRequired && err != ErrNoRequester
+}
+
+// process posts the activity to the inbox and forwards it, recovering from any
+// panic so that a single activity cannot stop the worker.
+func (q *InboxQueue) process(c context.Context, inboxIRI *url.URL, activity Activity) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &inboxPanicError{inboxIRI: inboxIRI, v: r}
+		}
+	}()
+	c = withOriginCheck(c)
+	if err = q.delegate.PostInbox(c, inboxIRI, activity); err != nil {
+		return
+	}
+	err = q.delegate.InboxForwarding(c, inboxIRI, activity)
+	return
+}
+
+// detachedContext keeps the values of a request's context, but is never done,
+// so that the work it was passed to outlives the request.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
@@ -0,0 +1,200 @@
+package pub
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingDelegate accepts every activity, and posts them to inboxes once
+// released. It panics on activities whose id ends in "panic", and fails to
+// post those whose id ends in "fail".
+type blockingDelegate struct {
+	DelegateActor
+	started chan string
+	release chan struct{}
+	mu      sync.Mutex
+	posted  []string
+}
+
+func (b *blockingDelegate) AuthenticatePostInbox(c context.Context, w http.ResponseWriter, r *http.Request) (bool, error) {
+	return false, nil
+}
+
+func (b *blockingDelegate) AuthorizePostInbox(c context.Context, w http.ResponseWriter, activity Activity) (bool, error) {
+	return false, nil
+}
+
+func (b *blockingDelegate) PostInbox(c context.Context, inboxIRI *url.URL, activity Activity) error {
+	id := activity.GetActivityStreamsId().Get().String()
+	b.started <- id
+	<-b.release
+	if strings.HasSuffix(id, "panic") {
+		panic("expected")
+	} else if strings.HasSuffix(id, "fail") {
+		return fmt.Errorf("expected")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.posted = append(b.posted, id)
+	return nil
+}
+
+func (b *blockingDelegate) InboxForwarding(c context.Context, inboxIRI *url.URL, activity Activity) error {
+	return nil
+}
+
+func TestAsyncInbox(t *testing.T) {
+	delegate := &blockingDelegate{
+		started: make(chan string, 3),
+		release: make(chan struct{}),
+	}
+	persister := NewMemoryInboxPersister()
+	var mu sync.Mutex
+	var errs []error
+	q, err := NewInboxQueue(1, 1, persister, func(c context.Context, inboxIRI *url.URL, activity Activity, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewCustomActor(delegate, false, true, fixedClock(time.Now()), WithAsyncInbox(q))
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(id string) int {
+		body := `{"@context":"https://www.w3.org/ns/activitystreams","id":"` + id + `","type":"Like","actor":"https://example.org/users/bob","object":"https://example.net/notes/1"}`
+		r := httptest.NewRequest("POST", "https://example.net/users/alice/inbox", strings.NewReader(body))
+		r.Header.Set(contentTypeHeader, activityJSONMediaType)
+		w := httptest.NewRecorder()
+		if handled, err := a.PostInbox(context.Background(), w, r); !handled || err != nil {
+			t.Fatalf("expected handled without error, got %v and %v", handled, err)
+		}
+		return w.Code
+	}
+	// The first activity occupies the worker, the second waits in the
+	// queue, and there is no room for the third.
+	if code := post("https://example.org/likes/panic"); code != http.StatusAccepted {
+		t.Fatalf("expected %v, got %v", http.StatusAccepted, code)
+	}
+	<-delegate.started
+	if code := post("https://example.org/likes/2"); code != http.StatusAccepted {
+		t.Fatalf("expected %v, got %v", http.StatusAccepted, code)
+	}
+	if code := post("https://example.org/likes/3"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected %v, got %v", http.StatusServiceUnavailable, code)
+	}
+	close(delegate.release)
+	q.Stop()
+	if len(delegate.posted) != 1 || delegate.posted[0] != "https://example.org/likes/2" {
+		t.Fatalf("expected the second activity posted, got %v", delegate.posted)
+	} else if len(errs) != 1 || !strings.Contains(errs[0].Error(), "panic") {
+		t.Fatalf("expected the panic reported, got %v", errs)
+	} else if len(persister.pending) != 0 {
+		t.Fatalf("expected no pending activities, got %d", len(persister.pending))
+	}
+}
+
+func TestInboxQueueResumePending(t *testing.T) {
+	delegate := &blockingDelegate{
+		started: make(chan string, 2),
+		release: make(chan struct{}),
+	}
+	close(delegate.release)
+	inbox, err := url.Parse("https://example.net/users/alice/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	persister := NewMemoryInboxPersister()
+	for _, id := range []string{"https://example.org/likes/1", "https://example.org/likes/2"} {
+		body := `{"@context":"https://www.w3.org/ns/activitystreams","id":"` + id + `","type":"Like","actor":"https://example.org/users/bob","object":"https://example.net/notes/1"}`
+		if _, err := persister.Received(context.Background(), []byte(body), []*url.URL{inbox}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q, err := NewInboxQueue(1, 2, persister, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCustomActor(delegate, false, true, fixedClock(time.Now()), WithAsyncInbox(q)); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.ResumePending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	q.Stop()
+	if len(delegate.posted) != 2 || delegate.posted[0] != "https://example.org/likes/1" || delegate.posted[1] != "https://example.org/likes/2" {
+		t.Fatalf("expected both activities posted in order, got %v", delegate.posted)
+	} else if p, err := persister.Pending(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if len(p) != 0 {
+		t.Fatalf("expected no pending activities, got %d", len(p))
+	}
+}
+
+func TestInboxQueueKeepsFailedActivities(t *testing.T) {
+	delegate := &blockingDelegate{
+		started: make(chan string, 1),
+		release: make(chan struct{}),
+	}
+	close(delegate.release)
+	inbox, err := url.Parse("https://example.net/users/alice/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	persister := NewMemoryInboxPersister()
+	body := `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.org/likes/fail","type":"Like","actor":"https://example.org/users/bob","object":"https://example.net/notes/1"}`
+	id, err := persister.Received(context.Background(), []byte(body), []*url.URL{inbox}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var errs []error
+	q, err := NewInboxQueue(1, 1, persister, func(c context.Context, inboxIRI *url.URL, activity Activity, err error) {
+		errs = append(errs, err)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCustomActor(delegate, false, true, fixedClock(time.Now()), WithAsyncInbox(q)); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.ResumePending(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	q.Stop()
+	if len(errs) != 1 {
+		t.Fatalf("expected the failure reported, got %v", errs)
+	} else if p, err := persister.Pending(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if len(p) != 1 || p[0].Id != id {
+		t.Fatalf("expected the failed activity pending, got %v", p)
+	}
+}
+
+func TestInboxQueueRejectsReuse(t *testing.T) {
+	q, err := NewInboxQueue(1, 1, NewMemoryInboxPersister(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Stop()
+	if _, err := NewCustomActor(&blockingDelegate{}, false, true, fixedClock(time.Now()), WithAsyncInbox(q)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCustomActor(&blockingDelegate{}, false, true, fixedClock(time.Now()), WithAsyncInbox(q)); err != ErrInboxQueueInUse {
+		t.Fatalf("expected %v, got %v", ErrInboxQueueInUse, err)
+	}
+}
+
+func TestNewInboxQueueRequiresPersister(t *testing.T) {
+	if _, err := NewInboxQueue(1, 1, nil, nil); err == nil {
+		t.Fatalf("expected an error without a persister")
+	}
+}
@@ -1,6 +1,10 @@
 package pub
 
 import (
+	"context"
+	"encoding/json"
+	"github.com/go-fed/activity/streams/vocab"
+	"net/http"
 	"testing"
 )
 
@@ -65,6 +69,41 @@ func TestHeaderIsActivityPubMediaType(t *testing.T) {
 			"application/ld+json;profile=\"https://www.w3.org/ns/activitystreams\"",
 			true,
 		},
+		{
+			"With Q-Values",
+			"application/ld+json; profile=\"https://www.w3.org/ns/activitystreams\", application/activity+json; q=0.9",
+			true,
+		},
+		{
+			"With Charset",
+			"application/activity+json; charset=utf-8",
+			true,
+		},
+		{
+			"Mixed Case",
+			"Application/Activity+JSON",
+			true,
+		},
+		{
+			"With Several Profiles",
+			"application/ld+json; profile=\"https://example.com/profile https://www.w3.org/ns/activitystreams\"",
+			true,
+		},
+		{
+			"Not Acceptable",
+			"application/activity+json; q=0, text/html",
+			false,
+		},
+		{
+			"Other Profile",
+			"application/ld+json; profile=\"https://www.w3.org/ns/activitystreams/extra\"",
+			false,
+		},
+		{
+			"HTML",
+			"text/html",
+			false,
+		},
 	}
 	for _, test := range tests {
 		if actual := headerIsActivityPubMediaType(test.input); actual != test.expected {
@@ -72,3 +111,107 @@ func TestHeaderIsActivityPubMediaType(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyDigest(t *testing.T) {
+	body := []byte(`{"type":"Note"}`)
+	const signedDigest = `keyId="k",headers="(request-target) host date digest",signature="s"`
+	tests := []struct {
+		name     string
+		header   string
+		signed   string
+		expected error
+	}{
+		{
+			"Matching Digest",
+			digest(body),
+			signedDigest,
+			nil,
+		},
+		{
+			"Lowercase Algorithm",
+			"sha-256" + digest(body)[len(sha256Digest):],
+			signedDigest,
+			nil,
+		},
+		{
+			"Multiple Digests",
+			"MD5=foo, " + digest(body),
+			signedDigest,
+			nil,
+		},
+		{
+			"Missing Digest",
+			"",
+			signedDigest,
+			ErrDigestMissing,
+		},
+		{
+			"Only Other Algorithms",
+			"MD5=foo",
+			signedDigest,
+			ErrDigestMissing,
+		},
+		{
+			"Mismatched Digest",
+			digest([]byte(`{"type":"Article"}`)),
+			signedDigest,
+			ErrDigestMismatch,
+		},
+		{
+			"Unsigned Digest",
+			digest(body),
+			`keyId="k",headers="(request-target) host date",signature="s"`,
+			ErrDigestUnsigned,
+		},
+	}
+	for _, test := range tests {
+		h := http.Header{}
+		if len(test.header) > 0 {
+			h.Set(digestHeader, test.header)
+		}
+		h.Set(signatureHeader, test.signed)
+		if actual := verifyDigest(h, body); actual != test.expected {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestGetInboxesPrefersSharedInbox(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    string
+		expected string
+	}{
+		{
+			name:     "Shared Inbox",
+			actor:    `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/users/alice","type":"Person","inbox":"https://example.com/users/alice/inbox","endpoints":{"sharedInbox":"https://example.com/inbox"}}`,
+			expected: "https://example.com/inbox",
+		},
+		{
+			name:     "No Endpoints",
+			actor:    `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/users/alice","type":"Person","inbox":"https://example.com/users/alice/inbox"}`,
+			expected: "https://example.com/users/alice/inbox",
+		},
+		{
+			name:     "No Shared Inbox",
+			actor:    `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/users/alice","type":"Person","inbox":"https://example.com/users/alice/inbox","endpoints":{"oauthTokenEndpoint":"https://example.com/token"}}`,
+			expected: "https://example.com/users/alice/inbox",
+		},
+	}
+	for _, test := range tests {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(test.actor), &m); err != nil {
+			t.Fatal(err)
+		}
+		actor, err := toType(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inboxes, err := getInboxes([]vocab.Type{actor})
+		if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if len(inboxes) != 1 || inboxes[0].String() != test.expected {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expected, inboxes)
+		}
+	}
+}
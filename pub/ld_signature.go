@@ -0,0 +1,262 @@
+package pub
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/go-fed/activity/jsonld"
+	"net/url"
+	"time"
+)
+
+const (
+	// The JSON key for an embedded Linked Data Signature.
+	signatureKey = "signature"
+	// The type of Linked Data Signature supported.
+	rsaSignature2017 = "RsaSignature2017"
+	// The JSON-LD context of the signature options that are hashed.
+	identityContext = "https://w3id.org/identity/v1"
+	// The JSON-LD context defining the 'signature' term.
+	securityContext = "https://w3id.org/security/v1"
+	// The JSON keys of an RsaSignature2017 block.
+	creatorKey        = "creator"
+	createdKey        = "created"
+	signatureValueKey = "signatureValue"
+)
+
+// Canonicalizer converts a JSON-LD document into a canonical form, so that
+// equivalent documents produce identical bytes to hash.
+//
+// Interoperating with other RsaSignature2017 implementations requires the
+// URDNA2015 algorithm serialized as N-Quads, as provided by
+// NewURDNA2015Canonicalizer.
+type Canonicalizer interface {
+	Canonicalize(doc map[string]interface{}) ([]byte, error)
+}
+
+// urdna2015Canonicalizer canonicalizes documents with the jsonld package.
+type urdna2015Canonicalizer struct {
+	load jsonld.ContextLoader
+}
+
+// NewURDNA2015Canonicalizer returns a Canonicalizer that converts documents
+// into RDF canonicalized by the URDNA2015 algorithm, serialized as N-Quads.
+//
+// Remote JSON-LD contexts are obtained from load, which should not fetch
+// arbitrary IRIs from the network. If it is nil, only the ActivityStreams,
+// security, and identity contexts embedded in the jsonld package are
+// available.
+func NewURDNA2015Canonicalizer(load jsonld.ContextLoader) Canonicalizer {
+	return urdna2015Canonicalizer{load: load}
+}
+
+// Canonicalize returns the sorted canonical N-Quads of the document.
+func (u urdna2015Canonicalizer) Canonicalize(doc map[string]interface{}) ([]byte, error) {
+	// Serialized values may hold Go types other than those decoded by
+	// encoding/json, such as ints.
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return nil, err
+	}
+	return jsonld.Canonicalize(decoded, u.load)
+}
+
+// LDSignatureError indicates the embedded Linked Data Signature of an activity
+// is missing, malformed, or does not verify against the key of its creator.
+type LDSignatureError struct {
+	KeyId string
+	Err   error
+}
+
+// Error returns a description of the failed Linked Data Signature.
+func (e *LDSignatureError) Error() string {
+	if len(e.KeyId) == 0 {
+		return fmt.Sprintf("missing or malformed linked data signature: %s", e.Err)
+	}
+	return fmt.Sprintf("linked data signature does not verify with key %q: %s", e.KeyId, e.Err)
+}
+
+// LDSigner embeds an RsaSignature2017 Linked Data Signature in the activities
+// an actor delivers.
+//
+// Unlike an HTTP Signature, the embedded signature travels with the activity,
+// so peers that receive a forwarded copy are still able to authenticate it.
+type LDSigner struct {
+	canon Canonicalizer
+	clock Clock
+	keys  KeyProvider
+}
+
+// NewLDSigner returns a new LDSigner that signs with the private key, whose
+// public key is dereferenceable at keyId.
+func NewLDSigner(canon Canonicalizer, clock Clock, keyId string, privKey *rsa.PrivateKey) *LDSigner {
+	return NewLDSignerWithKeyProvider(canon, clock, staticKeyProvider{
+		pubKeyId: keyId,
+		privKey:  privKey,
+	})
+}
+
+// NewLDSignerWithKeyProvider returns a new LDSigner that signs each activity
+// with the key currently supplied by the KeyProvider, which must be an RSA
+// key. Sharing the KeyProvider with the HttpSigTransport ensures that once an
+// actor's key is rotated, both signatures are made with the new key.
+func NewLDSignerWithKeyProvider(canon Canonicalizer, clock Clock, keys KeyProvider) *LDSigner {
+	return &LDSigner{
+		canon: canon,
+		clock: clock,
+		keys:  keys,
+	}
+}
+
+// Sign sets the 'signature' of the serialized activity, replacing any existing
+// one. The security vocabulary is added to the '@context' if it is absent.
+func (s *LDSigner) Sign(c context.Context, m map[string]interface{}) error {
+	keyId, key, err := s.keys.Key(c)
+	if err != nil {
+		return err
+	}
+	privKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("RsaSignature2017 requires an RSA private key: %T", key)
+	}
+	delete(m, signatureKey)
+	addSecurityContext(m)
+	options := map[string]interface{}{
+		creatorKey: keyId,
+		createdKey: s.clock.Now().UTC().Format(time.RFC3339),
+	}
+	hash, err := ldSignatureHash(s.canon, options, m)
+	if err != nil {
+		return err
+	}
+	sig, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, hash)
+	if err != nil {
+		return err
+	}
+	options[typePropertyKey] = rsaSignature2017
+	options[signatureValueKey] = base64.StdEncoding.EncodeToString(sig)
+	m[signatureKey] = options
+	return nil
+}
+
+// VerifyLDSignature verifies the RsaSignature2017 Linked Data Signature
+// embedded in the serialized activity, returning the IRI of the owner of the
+// key that created it.
+//
+// The boxIRI is passed to the newTransport function when dereferencing the
+// creator's key.
+func (h *HttpSigAuthenticator) VerifyLDSignature(c context.Context, boxIRI *url.URL, m map[string]interface{}) (owner *url.URL, err error) {
+	if h.canon == nil {
+		err = &LDSignatureError{Err: fmt.Errorf("linked data signatures are not accepted")}
+		return
+	}
+	sig, ok := m[signatureKey].(map[string]interface{})
+	if !ok {
+		err = &LDSignatureError{Err: fmt.Errorf("no signature")}
+		return
+	} else if sig[typePropertyKey] != rsaSignature2017 {
+		err = &LDSignatureError{Err: fmt.Errorf("unsupported signature type: %v", sig[typePropertyKey])}
+		return
+	}
+	keyId, _ := sig[creatorKey].(string)
+	created, _ := sig[createdKey].(string)
+	sigValue, _ := sig[signatureValueKey].(string)
+	if len(keyId) == 0 || len(created) == 0 || len(sigValue) == 0 {
+		err = &LDSignatureError{Err: fmt.Errorf("signature is missing creator, created, or signatureValue")}
+		return
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(sigValue)
+	if err != nil {
+		err = &LDSignatureError{Err: err}
+		return
+	}
+	doc := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if k != signatureKey {
+			doc[k] = v
+		}
+	}
+	options := map[string]interface{}{
+		creatorKey: keyId,
+		createdKey: created,
+	}
+	hash, err := ldSignatureHash(h.canon, options, doc)
+	if err != nil {
+		err = &LDSignatureError{KeyId: keyId, Err: err}
+		return
+	}
+	return h.verifyWithKey(c, boxIRI, keyId, func(pubKey crypto.PublicKey) error {
+		rsaKey, ok := pubKey.(*rsa.PublicKey)
+		if !ok {
+			return &KeyFormatError{KeyId: keyId, Err: fmt.Errorf("%s requires an RSA key, got %T", rsaSignature2017, pubKey)}
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, hash, sigBytes); err != nil {
+			return &LDSignatureError{KeyId: keyId, Err: err}
+		}
+		return nil
+	})
+}
+
+// ldSignatureHash computes the SHA-256 digest that an RsaSignature2017 signs:
+// the hex encoded hash of the canonical signature options followed by the hex
+// encoded hash of the canonical document.
+func ldSignatureHash(canon Canonicalizer, options, doc map[string]interface{}) ([]byte, error) {
+	withContext := make(map[string]interface{}, len(options)+1)
+	for k, v := range options {
+		withContext[k] = v
+	}
+	withContext[jsonLDContext] = identityContext
+	optionsHash, err := canonicalHash(canon, withContext)
+	if err != nil {
+		return nil, err
+	}
+	docHash, err := canonicalHash(canon, doc)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256([]byte(optionsHash + docHash))
+	return h[:], nil
+}
+
+// canonicalHash returns the hex encoded SHA-256 hash of the canonical form of
+// the document.
+func canonicalHash(canon Canonicalizer, doc map[string]interface{}) (string, error) {
+	b, err := canon.Canonicalize(doc)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// addSecurityContext adds the security vocabulary to the '@context' of the
+// serialized value, so that its 'signature' term is defined.
+func addSecurityContext(m map[string]interface{}) {
+	switch v := m[jsonLDContext].(type) {
+	case nil:
+		m[jsonLDContext] = securityContext
+	case string:
+		if v != securityContext {
+			m[jsonLDContext] = []interface{}{v, securityContext}
+		}
+	case []interface{}:
+		for _, elem := range v {
+			if elem == securityContext {
+				return
+			}
+		}
+		m[jsonLDContext] = append(v, securityContext)
+	default:
+		m[jsonLDContext] = []interface{}{v, securityContext}
+	}
+}
@@ -0,0 +1,251 @@
+package pub
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// jsonCanonicalizer canonicalizes by marshalling, which sorts the keys of
+// maps. It is only suitable for tests.
+type jsonCanonicalizer struct{}
+
+func (jsonCanonicalizer) Canonicalize(doc map[string]interface{}) ([]byte, error) {
+	return json.Marshal(doc)
+}
+
+// fixedClock always returns the same time.
+type fixedClock time.Time
+
+func (f fixedClock) Now() time.Time {
+	return time.Time(f)
+}
+
+// mapTransport dereferences IRIs, ignoring fragments, to documents.
+type mapTransport struct {
+	Transport
+	docs map[string][]byte
+}
+
+func (m mapTransport) Dereference(c context.Context, iri *url.URL) ([]byte, error) {
+	u := *iri
+	u.Fragment = ""
+	if b, ok := m.docs[u.String()]; ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("not found: %s", iri)
+}
+
+func testActorDoc(id, pubPem string) []byte {
+	return []byte(fmt.Sprintf(`{"@context":["https://www.w3.org/ns/activitystreams","https://w3id.org/security/v1"],"id":%q,"type":"Person","publicKey":{"id":%q,"owner":%q,"publicKeyPem":%q}}`,
+		id, id+"#main-key", id, pubPem))
+}
+
+func TestLDSignatureRoundTrip(t *testing.T) {
+	privKey, pubPem := newTestKeyPair(t)
+	canon := NewURDNA2015Canonicalizer(nil)
+	signer := NewLDSigner(canon, fixedClock(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)), testKeyId, privKey)
+	a := NewHttpSigAuthenticatorWithLDSignatures(func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+		return mapTransport{docs: map[string][]byte{testKeyOwner: testActorDoc(testKeyOwner, pubPem)}}, nil
+	}, canon)
+	tests := []struct {
+		name    string
+		modify  func(m map[string]interface{})
+		checkFn func(err error) bool
+	}{
+		{
+			name:    "unmodified",
+			modify:  func(m map[string]interface{}) {},
+			checkFn: func(err error) bool { return err == nil },
+		},
+		{
+			name:   "tampered",
+			modify: func(m map[string]interface{}) { m["object"] = "https://example.net/note/2" },
+			checkFn: func(err error) bool {
+				_, ok := err.(*LDSignatureError)
+				return ok
+			},
+		},
+		{
+			name:   "unsigned",
+			modify: func(m map[string]interface{}) { delete(m, signatureKey) },
+			checkFn: func(err error) bool {
+				_, ok := err.(*LDSignatureError)
+				return ok
+			},
+		},
+	}
+	boxIRI, err := url.Parse("https://example.net/users/bob/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		m := map[string]interface{}{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"id":       "https://example.com/activity/1",
+			"type":     "Like",
+			"actor":    testKeyOwner,
+			"object":   "https://example.net/note/1",
+		}
+		if err := signer.Sign(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		test.modify(m)
+		owner, err := a.VerifyLDSignature(context.Background(), boxIRI, m)
+		if !test.checkFn(err) {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if err == nil && owner.String() != testKeyOwner {
+			t.Fatalf("(%q): expected %v, got %v", test.name, testKeyOwner, owner)
+		}
+	}
+}
+
+func TestHttpSigAuthenticatorAuthenticatePostInboxForwarded(t *testing.T) {
+	const forwarder = "https://example.org/users/carol"
+	authorKey, authorPem := newTestKeyPair(t)
+	forwarderKey, forwarderPem := newTestKeyPair(t)
+	docs := map[string][]byte{
+		testKeyOwner: testActorDoc(testKeyOwner, authorPem),
+		forwarder:    testActorDoc(forwarder, forwarderPem),
+	}
+	signer := NewLDSigner(jsonCanonicalizer{}, fixedClock(time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)), testKeyId, authorKey)
+	tests := []struct {
+		name     string
+		ldSign   bool
+		acceptLD bool
+		code     int
+	}{
+		{
+			name:     "linked data signature",
+			ldSign:   true,
+			acceptLD: true,
+			code:     http.StatusOK,
+		},
+		{
+			name:     "linked data signatures not accepted",
+			ldSign:   true,
+			acceptLD: false,
+			code:     http.StatusForbidden,
+		},
+		{
+			name:     "no linked data signature",
+			acceptLD: true,
+			code:     http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		m := map[string]interface{}{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"id":       "https://example.com/activity/1",
+			"type":     "Like",
+			"actor":    testKeyOwner,
+			"object":   "https://example.net/note/1",
+		}
+		if test.ldSign {
+			if err := signer.Sign(context.Background(), m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		body, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest("POST", "https://example.net/users/bob/inbox", bytes.NewReader(body))
+		r.Header.Set(dateHeader, "Mon, 02 Jan 2006 15:04:05 GMT")
+		signTestRequest(t, r, body, forwarderKey, forwarder+"#main-key")
+		var canon Canonicalizer
+		if test.acceptLD {
+			canon = jsonCanonicalizer{}
+		}
+		a := NewHttpSigAuthenticatorWithLDSignatures(func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+			return mapTransport{docs: docs}, nil
+		}, canon)
+		w := httptest.NewRecorder()
+		if _, err = a.AuthenticatePostInbox(context.Background(), w, r); err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if w.Code != test.code {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.code, w.Code)
+		}
+	}
+}
+
+// TestVerifyLDSignatureSignedIndependently verifies a signature made the way
+// Mastodon makes RsaSignature2017 signatures, from the hex SHA-256 hashes of
+// the canonical N-Quads of the signature options and of the document, written
+// out here rather than produced by the canonicalizer.
+func TestVerifyLDSignatureSignedIndependently(t *testing.T) {
+	const (
+		options = `_:c14n0 <http://purl.org/dc/terms/created> "2020-01-01T00:00:00Z"^^<http://www.w3.org/2001/XMLSchema#dateTime> .
+_:c14n0 <http://purl.org/dc/terms/creator> <https://example.com/users/alice#main-key> .
+`
+		doc = `<https://example.com/activity/1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://www.w3.org/ns/activitystreams#Like> .
+<https://example.com/activity/1> <https://www.w3.org/ns/activitystreams#actor> <https://example.com/users/alice> .
+<https://example.com/activity/1> <https://www.w3.org/ns/activitystreams#object> <https://example.net/note/1> .
+`
+	)
+	privKey, pubPem := newTestKeyPair(t)
+	optionsHash := sha256.Sum256([]byte(options))
+	docHash := sha256.Sum256([]byte(doc))
+	toBeSigned := sha256.Sum256([]byte(hex.EncodeToString(optionsHash[:]) + hex.EncodeToString(docHash[:])))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, toBeSigned[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := map[string]interface{}{
+		"@context": []interface{}{"https://www.w3.org/ns/activitystreams", "https://w3id.org/security/v1"},
+		"id":       "https://example.com/activity/1",
+		"type":     "Like",
+		"actor":    testKeyOwner,
+		"object":   "https://example.net/note/1",
+		"signature": map[string]interface{}{
+			"type":           "RsaSignature2017",
+			"creator":        testKeyId,
+			"created":        "2020-01-01T00:00:00Z",
+			"signatureValue": base64.StdEncoding.EncodeToString(sig),
+		},
+	}
+	a := NewHttpSigAuthenticatorWithLDSignatures(func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+		return mapTransport{docs: map[string][]byte{testKeyOwner: testActorDoc(testKeyOwner, pubPem)}}, nil
+	}, NewURDNA2015Canonicalizer(nil))
+	boxIRI, err := url.Parse("https://example.net/users/bob/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := a.VerifyLDSignature(context.Background(), boxIRI, m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if owner.String() != testKeyOwner {
+		t.Fatalf("expected %v, got %v", testKeyOwner, owner)
+	}
+}
+
+func TestLDSignerKeyProvider(t *testing.T) {
+	privKey, _ := newTestKeyPair(t)
+	keyId := testKeyId
+	signer := NewLDSignerWithKeyProvider(jsonCanonicalizer{}, fixedClock(time.Now()), rotatingKeyProvider{pubKeyId: &keyId, privKey: privKey})
+	for _, rotated := range []string{testKeyId, "https://example.com/users/alice#key-2"} {
+		keyId = rotated
+		m := map[string]interface{}{
+			"@context": "https://www.w3.org/ns/activitystreams",
+			"id":       "https://example.com/activity/1",
+			"type":     "Like",
+		}
+		if err := signer.Sign(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		if actual := m[signatureKey].(map[string]interface{})[creatorKey]; actual != rotated {
+			t.Fatalf("expected %v, got %v", rotated, actual)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package pub
+
+import (
+	"time"
+)
+
+// ActorOption configures optional behavior of an Actor when it is created.
+type ActorOption func(b *baseActor)
+
+// WithDigestVerification requires that POST requests to an inbox have a
+// SHA-256 Digest header matching the request body, and covered by the
+// request's HTTP Signature. Requests without the header, whose digest does not
+// match, or whose signature does not cover it, are rejected with a Bad Request
+// status before the body is parsed.
+func WithDigestVerification() ActorOption {
+	return func(b *baseActor) {
+		b.requireDigest = true
+	}
+}
+
+// WithAuthorizedFetch requires that GET requests to an inbox or outbox have a
+// valid HTTP Signature, verified by the HttpSigAuthenticator.
+//
+// Requests that are unsigned, or whose signature does not verify, are answered
+// with an Unauthorized status. Otherwise the owner of the signing key is set in
+// the context passed to the application, and is available with
+// RequesterFromContext to filter the collection served.
+func WithAuthorizedFetch(v *HttpSigAuthenticator) ActorOption {
+	return func(b *baseActor) {
+		b.fetchVerifier = v
+	}
+}
+
+// WithLDSigner embeds a Linked Data Signature in the activities delivered from
+// an outbox, so that recipients forwarding them to their own followers do not
+// prevent the activities from being authenticated.
+//
+// It has no effect on an Actor created with NewCustomActor.
+func WithLDSigner(s *LDSigner) ActorOption {
+	return func(b *baseActor) {
+		if a, ok := b.delegate.(*sideEffectActor); ok {
+			a.ldSigner = s
+		}
+	}
+}
+
+// WithReplayProtection rejects POST requests to an inbox whose Date header is
+// more than the skew away from the current time, or that repeat a request
+// already accepted within that window, with an Unauthorized status.
+//
+// Accepted requests are recorded in the ReplayStore by the keyId of their HTTP
+// Signature, their host and request target, their date, and the digest of
+// their body, after the FederatingProtocol authenticates them.
+func WithReplayProtection(skew time.Duration, store ReplayStore) ActorOption {
+	return func(b *baseActor) {
+		b.replaySkew = skew
+		b.replayStore = store
+	}
+}
+
+// WithDeliverer hands activities to the Deliverer to be sent to their
+// recipients, rather than sending them before the request is answered. This
+// includes activities delivered from an outbox, forwarded from an inbox, and
+// sent in response to a Follow.
+//
+// Activities delivered from an outbox to actors on this server are the
+// exception: they are posted to their inboxes before the request is answered,
+// without making any requests. A Deliverer that persists its deliveries, such
+// as one using a deliverer.FilePersister, would otherwise replay them after a
+// restart with the sendFn it is given, over HTTP to this server.
+//
+// Delivery failures are then reported by the Deliverer instead of being
+// returned to the caller.
+//
+// It has no effect on an Actor created with NewCustomActor.
+func WithDeliverer(d Deliverer) ActorOption {
+	return func(b *baseActor) {
+		if a, ok := b.delegate.(*sideEffectActor); ok {
+			a.deliverer = d
+		}
+	}
+}
+
+// WithAsyncInbox answers POST requests to an inbox with an Accepted status as
+// soon as the activity is authenticated, authorized, and persisted, then posts
+// it to the inboxes and forwards it in the background with the InboxQueue.
+//
+// Errors that would have been returned to the caller of PostInbox or
+// PostSharedInbox, including the Bad Request status for ErrObjectRequired and
+// ErrTargetRequired, are instead passed to the queue's error handler.
+//
+// The InboxQueue cannot be shared with another Actor. Creating an Actor with
+// one that is already used returns ErrInboxQueueInUse.
+func WithAsyncInbox(q *InboxQueue) ActorOption {
+	return func(b *baseActor) {
+		b.inboxQueue = q
+	}
+}
+
+// WithPageSize sets the number of items on each page of an inbox or outbox
+// whose Database is an InboxPager or OutboxPager. It is 20 by default.
+//
+// It has no effect on an Actor created with NewCustomActor.
+func WithPageSize(n int) ActorOption {
+	return func(b *baseActor) {
+		if a, ok := b.delegate.(*sideEffectActor); ok {
+			a.pageSize = n
+		}
+	}
+}
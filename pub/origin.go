@@ -0,0 +1,234 @@
+package pub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OriginPolicy enumerates what the go-fed library can do with an object
+// embedded in an activity received from a peer, when the object's id is on a
+// different host than the peer that sent it. Such a copy may have been forged
+// by the sender.
+//
+// The zero value is OriginDereference, so that such copies are only trusted
+// when an application opts in with OriginTrust.
+type OriginPolicy int
+
+const (
+	// OriginDereference replaces the embedded object with the copy
+	// dereferenced from its id, on its own host.
+	OriginDereference OriginPolicy = iota
+	// OriginReject rejects the activity with an *OriginMismatchError.
+	OriginReject
+	// OriginTrust uses the embedded object as it was received.
+	OriginTrust
+)
+
+// OriginMismatchError is returned when an activity is rejected for embedding
+// an object whose id is not on the host of the peer that sent it.
+type OriginMismatchError struct {
+	// Object is the id of the embedded object.
+	Object *url.URL
+	// Hosts are those of the peer that sent the activity.
+	Hosts []string
+}
+
+func (e *OriginMismatchError) Error() string {
+	return fmt.Sprintf("object %s is not on the host of its sender: %s", e.Object, strings.Join(e.Hosts, ", "))
+}
+
+// senderHosts returns the hosts of the peer that sent an activity, that of the
+// requester verified when authenticating the request. The actors the activity
+// claims are not trusted, as a peer may embed objects on their behalf.
+func senderHosts(c context.Context) (map[string]bool, error) {
+	requester := RequesterFromContext(c)
+	if requester == nil {
+		return nil, ErrNoRequester
+	}
+	return map[string]bool{requester.Host: true}, nil
+}
+
+// originCheckKey is the context key of the originCheck of an activity.
+type originCheckKey struct{}
+
+// originCheck records the activity resulting from applying an origin policy
+// while posting an activity to an inbox, so that inbox forwarding uses it in
+// place of the activity as received.
+type originCheck struct {
+	mu         sync.Mutex
+	checked    Activity
+	asReceived bool
+}
+
+// withOriginCheck returns a copy of the context in which the origin check of
+// an activity posted to an inbox is recorded.
+func withOriginCheck(c context.Context) context.Context {
+	return context.WithValue(c, originCheckKey{}, &originCheck{})
+}
+
+// recordOriginCheck records in the context the activity resulting from
+// applying an origin policy, if the context has an originCheck.
+func recordOriginCheck(c context.Context, received, checked Activity) {
+	o, ok := c.Value(originCheckKey{}).(*originCheck)
+	if !ok {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.checked = checked
+	o.asReceived = checked == received
+}
+
+// checkedActivity returns the activity recorded in the context by applying an
+// origin policy, and whether its objects are used as received. The activity
+// is returned as received if no origin policy was applied to it.
+func checkedActivity(c context.Context, received Activity) (Activity, bool) {
+	o, ok := c.Value(originCheckKey{}).(*originCheck)
+	if !ok {
+		return received, true
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.checked == nil {
+		return received, true
+	}
+	return o.checked, o.asReceived
+}
+
+// applyOriginPolicy checks the origin of each object embedded in the activity
+// according to the policy. It returns the activity as received if all of them
+// are on the sender's host, or if the policy is OriginTrust. Otherwise it
+// either rejects the activity, or returns a copy of the activity in which the
+// objects that are not are replaced by their dereferenced copies.
+//
+// The result is recorded in the context for InboxForwarding.
+func (w FederatingWrappedCallbacks) applyOriginPolicy(c context.Context, a Activity, policy OriginPolicy) (Activity, error) {
+	checked, err := w.checkOrigin(c, a, policy)
+	if err != nil {
+		return nil, err
+	}
+	recordOriginCheck(c, a, checked)
+	return checked, nil
+}
+
+// checkOrigin implements applyOriginPolicy.
+func (w FederatingWrappedCallbacks) checkOrigin(c context.Context, a Activity, policy OriginPolicy) (Activity, error) {
+	if policy == OriginTrust {
+		return a, nil
+	}
+	foreign, hosts, err := foreignObjects(c, a)
+	if err != nil {
+		return nil, err
+	}
+	// The dereferenced copies, by their index in the 'object' property.
+	canonical := make(map[int]map[string]interface{})
+	for i, id := range foreign {
+		if id == nil {
+			continue
+		} else if policy == OriginReject {
+			return nil, &OriginMismatchError{Object: id, Hosts: sortedHosts(hosts)}
+		}
+		if canonical[i], err = w.dereferenceObject(c, id); err != nil {
+			return nil, err
+		}
+	}
+	if len(canonical) == 0 {
+		return a, nil
+	}
+	// Replace the objects in the serialized activity, then deserialize it
+	// again.
+	m, err := serialize(a)
+	if err != nil {
+		return nil, err
+	}
+	objects, ok := m["object"].([]interface{})
+	if !ok {
+		objects = []interface{}{m["object"]}
+	}
+	for i, obj := range canonical {
+		objects[i] = obj
+	}
+	if len(objects) == 1 {
+		m["object"] = objects[0]
+	} else {
+		m["object"] = objects
+	}
+	t, err := toType(c, m)
+	if err != nil {
+		return nil, err
+	}
+	activity, ok := t.(Activity)
+	if !ok {
+		return nil, fmt.Errorf("activity streams value is not an Activity: %T", t)
+	}
+	return activity, nil
+}
+
+// foreignObjects returns, for each value of the activity's 'object' property,
+// the id of the embedded object if it is not on the host of its sender, or nil.
+// It also returns the sender's hosts.
+//
+// An embedded object without an id is transient, and so is the sender's own.
+func foreignObjects(c context.Context, a Activity) ([]*url.URL, map[string]bool, error) {
+	op := a.GetActivityStreamsObject()
+	if op == nil || op.Len() == 0 {
+		return nil, nil, nil
+	}
+	hosts, err := senderHosts(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	foreign := make([]*url.URL, op.Len())
+	for i := 0; i < op.Len(); i++ {
+		t := op.At(i).GetType()
+		if t == nil {
+			// An IRI does not claim to be a copy of anything.
+			continue
+		}
+		id, err := GetId(t)
+		if err != nil {
+			// An object without an id is transient.
+			continue
+		}
+		if !hosts[id.Host] {
+			foreign[i] = id
+		}
+	}
+	return foreign, hosts, nil
+}
+
+// dereferenceObject fetches the object at its id, ensuring the copy served has
+// the same id.
+func (w FederatingWrappedCallbacks) dereferenceObject(c context.Context, id *url.URL) (map[string]interface{}, error) {
+	t, err := w.newTransport(c, w.inboxIRI, goFedUserAgent())
+	if err != nil {
+		return nil, err
+	}
+	b, err := t.Dereference(c, id)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	if v, _ := m["id"].(string); v != id.String() {
+		return nil, fmt.Errorf("object dereferenced from %s has a different id: %v", id, m["id"])
+	}
+	delete(m, jsonLDContext)
+	return m, nil
+}
+
+func sortedHosts(hosts map[string]bool) []string {
+	s := make([]string, 0, len(hosts))
+	for h := range hosts {
+		s = append(s, h)
+	}
+	sort.Strings(s)
+	return s
+}
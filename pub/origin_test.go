@@ -0,0 +1,236 @@
+package pub
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-fed/activity/streams/vocab"
+	"net/url"
+	"testing"
+)
+
+// creatingDatabase records the content of the objects created.
+type creatingDatabase struct {
+	Database
+	created []string
+}
+
+func (d *creatingDatabase) Lock(c context.Context, id *url.URL) error   { return nil }
+func (d *creatingDatabase) Unlock(c context.Context, id *url.URL) error { return nil }
+
+func (d *creatingDatabase) Create(c context.Context, t vocab.Type) error {
+	m, err := t.Serialize()
+	if err != nil {
+		return err
+	}
+	d.created = append(d.created, m["content"].(string))
+	return nil
+}
+
+func TestCreateOriginPolicy(t *testing.T) {
+	const (
+		forged  = `{"id":"https://example.com/notes/1","type":"Note","content":"forged"}`
+		fetched = `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/notes/1","type":"Note","content":"fetched"}`
+		local   = `{"id":"https://example.org/notes/2","type":"Note","content":"local"}`
+		// The sender of the activities, unless the test has another.
+		bob = "https://example.org/users/bob"
+	)
+	tests := []struct {
+		name      string
+		objects   string
+		policy    OriginPolicy
+		requester string
+		expect    []string
+		expectErr bool
+	}{
+		{
+			name:    "trust",
+			objects: forged,
+			policy:  OriginTrust,
+			expect:  []string{"forged"},
+		},
+		{
+			name:      "reject",
+			objects:   "[" + local + "," + forged + "]",
+			policy:    OriginReject,
+			expectErr: true,
+		},
+		{
+			name:    "reject same host",
+			objects: local,
+			policy:  OriginReject,
+			expect:  []string{"local"},
+		},
+		{
+			name:      "reject declared actor's host",
+			objects:   local,
+			policy:    OriginReject,
+			requester: "https://example.com/users/mallory",
+			expectErr: true,
+		},
+		{
+			name:    "reject transient",
+			objects: `{"type":"Note","content":"transient"}`,
+			policy:  OriginReject,
+		},
+		{
+			name:    "default",
+			objects: "[" + local + "," + forged + "]",
+			expect:  []string{"local", "fetched"},
+		},
+		{
+			name:    "dereference",
+			objects: "[" + local + "," + forged + "]",
+			policy:  OriginDereference,
+			expect:  []string{"local", "fetched"},
+		},
+	}
+	for _, test := range tests {
+		var m map[string]interface{}
+		raw := `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.org/creates/1","type":"Create","actor":"` + bob + `","object":` + test.objects + `}`
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			t.Fatal(err)
+		}
+		a, err := toType(context.Background(), m)
+		if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		db := &creatingDatabase{}
+		w := FederatingWrappedCallbacks{
+			CreateOrigin: test.policy,
+			db:           db,
+			newTransport: func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+				return mapTransport{docs: map[string][]byte{"https://example.com/notes/1": []byte(fetched)}}, nil
+			},
+		}
+		requester, err := url.Parse(bob)
+		if test.requester != "" {
+			requester, err = url.Parse(test.requester)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = w.create(WithRequester(context.Background(), requester), a.(vocab.ActivityStreamsCreate))
+		if test.expectErr {
+			if _, ok := err.(*OriginMismatchError); !ok {
+				t.Fatalf("(%q): expected *OriginMismatchError, got %v", test.name, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		if len(db.created) != len(test.expect) {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expect, db.created)
+		}
+		for i := range test.expect {
+			if db.created[i] != test.expect[i] {
+				t.Fatalf("(%q): expected %v, got %v", test.name, test.expect, db.created)
+			}
+		}
+	}
+}
+
+// forwardingDatabase has seen no activities, records the content of the object
+// of the Create it stores, and counts the recipients it is asked whether it
+// owns while forwarding.
+type forwardingDatabase struct {
+	Database
+	stored string
+	owns   int
+}
+
+func (d *forwardingDatabase) Lock(c context.Context, id *url.URL) error           { return nil }
+func (d *forwardingDatabase) Unlock(c context.Context, id *url.URL) error         { return nil }
+func (d *forwardingDatabase) Exists(c context.Context, id *url.URL) (bool, error) { return false, nil }
+
+func (d *forwardingDatabase) Create(c context.Context, t vocab.Type) error {
+	create, ok := t.(vocab.ActivityStreamsCreate)
+	if !ok {
+		return nil
+	}
+	m, err := create.Serialize()
+	if err != nil {
+		return err
+	}
+	d.stored = m["object"].(map[string]interface{})["content"].(string)
+	return nil
+}
+
+func (d *forwardingDatabase) Owns(c context.Context, id *url.URL) (bool, error) {
+	d.owns++
+	return false, nil
+}
+
+func TestInboxForwardingOriginPolicy(t *testing.T) {
+	const (
+		forged  = `{"id":"https://example.com/notes/1","type":"Note","content":"forged"}`
+		fetched = `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/notes/1","type":"Note","content":"fetched"}`
+		local   = `{"id":"https://example.org/notes/2","type":"Note","content":"local"}`
+	)
+	inboxIRI, err := url.Parse("https://example.net/users/alice/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := url.Parse("https://example.org/users/bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		objects string
+		policy  OriginPolicy
+		stored  string
+		forward bool
+	}{
+		{
+			name:    "default",
+			objects: forged,
+			stored:  "fetched",
+		},
+		{
+			name:    "trust",
+			objects: forged,
+			policy:  OriginTrust,
+			stored:  "forged",
+			forward: true,
+		},
+		{
+			name:    "same host",
+			objects: local,
+			stored:  "local",
+			forward: true,
+		},
+	}
+	for _, test := range tests {
+		var m map[string]interface{}
+		raw := `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.org/creates/1","type":"Create","actor":"https://example.org/users/bob","to":"https://example.net/users/alice/followers","cc":"https://example.net/users/alice","audience":"https://example.net/users/alice","object":` + test.objects + `}`
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			t.Fatal(err)
+		}
+		v, err := toType(context.Background(), m)
+		if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		db := &forwardingDatabase{}
+		w := FederatingWrappedCallbacks{
+			CreateOrigin: test.policy,
+			db:           db,
+			newTransport: func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+				return mapTransport{docs: map[string][]byte{"https://example.com/notes/1": []byte(fetched)}}, nil
+			},
+		}
+		// As when posted to the inbox, the side effects are applied
+		// before forwarding in the same context.
+		c := withOriginCheck(WithRequester(context.Background(), bob))
+		if err := w.create(c, v.(vocab.ActivityStreamsCreate)); err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		a := &sideEffectActor{db: db}
+		if err := a.InboxForwarding(c, inboxIRI, v.(Activity)); err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if db.stored != test.stored {
+			t.Fatalf("(%q): expected stored %q, got %q", test.name, test.stored, db.stored)
+		} else if forwarded := db.owns > 0; forwarded != test.forward {
+			t.Fatalf("(%q): expected forwarding %v, got %v", test.name, test.forward, forwarded)
+		}
+	}
+}
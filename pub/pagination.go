@@ -0,0 +1,119 @@
+package pub
+
+import (
+	"context"
+	"github.com/go-fed/activity/streams"
+	"github.com/go-fed/activity/streams/vocab"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// pageQuery is the query parameter requesting a page of a collection
+	// rather than the collection itself.
+	pageQuery = "page"
+	// cursorQuery is the query parameter with the cursor of the page
+	// requested.
+	cursorQuery = "cursor"
+	// defaultPageSize is the number of items on each page of an inbox or
+	// outbox, unless set by WithPageSize.
+	defaultPageSize = 20
+)
+
+var _ PagedBoxDelegate = &sideEffectActor{}
+
+// GetPagedInbox pages the inbox if the Database is an InboxPager.
+func (a *sideEffectActor) GetPagedInbox(c context.Context, r *http.Request) (vocab.Type, bool, error) {
+	p, ok := a.db.(InboxPager)
+	if !ok {
+		return nil, false, nil
+	}
+	t, err := a.pagedBox(c, r, p.InboxIRI, p.InboxTotalItems, p.InboxPage)
+	return t, true, err
+}
+
+// GetPagedOutbox pages the outbox if the Database is an OutboxPager.
+func (a *sideEffectActor) GetPagedOutbox(c context.Context, r *http.Request) (vocab.Type, bool, error) {
+	p, ok := a.db.(OutboxPager)
+	if !ok {
+		return nil, false, nil
+	}
+	t, err := a.pagedBox(c, r, p.OutboxIRI, p.OutboxTotalItems, p.OutboxPage)
+	return t, true, err
+}
+
+// pagedBox builds the OrderedCollection of an inbox or outbox, or the
+// OrderedCollectionPage requested.
+func (a *sideEffectActor) pagedBox(c context.Context,
+	r *http.Request,
+	iriFn func(c context.Context, r *http.Request) (*url.URL, error),
+	totalFn func(c context.Context, boxIRI *url.URL) (int, error),
+	pageFn func(c context.Context, boxIRI *url.URL, cursor string, limit int) (BoxPage, error)) (vocab.Type, error) {
+	boxIRI, err := iriFn(c, r)
+	if err != nil {
+		return nil, err
+	}
+	q := r.URL.Query()
+	if _, ok := q[pageQuery]; !ok {
+		n, err := totalFn(c, boxIRI)
+		if err != nil {
+			return nil, err
+		}
+		oc := streams.NewActivityStreamsOrderedCollection()
+		id := streams.NewActivityStreamsIdProperty()
+		id.Set(boxIRI)
+		oc.SetActivityStreamsId(id)
+		total := streams.NewActivityStreamsTotalItemsProperty()
+		total.Set(n)
+		oc.SetActivityStreamsTotalItems(total)
+		first := streams.NewActivityStreamsFirstProperty()
+		first.SetIRI(pageIRI(boxIRI, ""))
+		oc.SetActivityStreamsFirst(first)
+		return oc, nil
+	}
+	pageSize := a.pageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	cursor := q.Get(cursorQuery)
+	page, err := pageFn(c, boxIRI, cursor, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	ocp := streams.NewActivityStreamsOrderedCollectionPage()
+	id := streams.NewActivityStreamsIdProperty()
+	id.Set(pageIRI(boxIRI, cursor))
+	ocp.SetActivityStreamsId(id)
+	partOf := streams.NewActivityStreamsPartOfProperty()
+	partOf.SetIRI(boxIRI)
+	ocp.SetActivityStreamsPartOf(partOf)
+	oi := streams.NewActivityStreamsOrderedItemsProperty()
+	for _, item := range page.Items {
+		oi.AppendIRI(item)
+	}
+	ocp.SetActivityStreamsOrderedItems(oi)
+	if len(page.Next) > 0 {
+		next := streams.NewActivityStreamsNextProperty()
+		next.SetIRI(pageIRI(boxIRI, page.Next))
+		ocp.SetActivityStreamsNext(next)
+	}
+	if len(page.Prev) > 0 {
+		prev := streams.NewActivityStreamsPrevProperty()
+		prev.SetIRI(pageIRI(boxIRI, page.Prev))
+		ocp.SetActivityStreamsPrev(prev)
+	}
+	return ocp, nil
+}
+
+// pageIRI returns the IRI of the page of the collection at the cursor, which is
+// empty for the first page. Any query of the collection's IRI is kept.
+func pageIRI(collectionIRI *url.URL, cursor string) *url.URL {
+	u := *collectionIRI
+	q := collectionIRI.Query()
+	q.Set(pageQuery, "true")
+	if len(cursor) > 0 {
+		q.Set(cursorQuery, cursor)
+	}
+	u.RawQuery = q.Encode()
+	return &u
+}
@@ -0,0 +1,231 @@
+package pub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// pagingDatabase pages the inbox of items at inbox, with cursors that are the
+// index of the first item on the page.
+type pagingDatabase struct {
+	Database
+	inbox *url.URL
+	items []*url.URL
+}
+
+func (p pagingDatabase) InboxIRI(c context.Context, r *http.Request) (*url.URL, error) {
+	return p.inbox, nil
+}
+
+func (p pagingDatabase) InboxTotalItems(c context.Context, inboxIRI *url.URL) (int, error) {
+	return len(p.items), nil
+}
+
+func (p pagingDatabase) InboxPage(c context.Context, inboxIRI *url.URL, cursor string, limit int) (BoxPage, error) {
+	start := 0
+	if len(cursor) > 0 {
+		var err error
+		if start, err = strconv.Atoi(cursor); err != nil || start < 0 || start > len(p.items) {
+			return BoxPage{}, ErrInvalidCursor
+		}
+	}
+	end := start + limit
+	if end > len(p.items) {
+		end = len(p.items)
+	}
+	page := BoxPage{Items: p.items[start:end]}
+	if end < len(p.items) {
+		page.Next = strconv.Itoa(end)
+	}
+	if start > 0 {
+		prev := start - limit
+		if prev < 0 {
+			prev = 0
+		}
+		page.Prev = strconv.Itoa(prev)
+	}
+	return page, nil
+}
+
+// permissiveBehavior permits every GET.
+type permissiveBehavior struct {
+	CommonBehavior
+}
+
+func (permissiveBehavior) AuthenticateGetInbox(c context.Context, w http.ResponseWriter, r *http.Request) (bool, error) {
+	return false, nil
+}
+
+func TestGetPagedInbox(t *testing.T) {
+	const inbox = "https://example.net/users/alice/inbox"
+	inboxIRI, err := url.Parse(inbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := pagingDatabase{inbox: inboxIRI}
+	for i := 0; i < 3; i++ {
+		u, err := url.Parse("https://example.org/activities/" + strconv.Itoa(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.items = append(db.items, u)
+	}
+	a, err := NewFederatingActor(permissiveBehavior{}, nil, db, fixedClock(time.Now()), WithPageSize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		query  string
+		expect map[string]interface{}
+	}{
+		{
+			name: "collection",
+			expect: map[string]interface{}{
+				"id":         inbox,
+				"type":       "OrderedCollection",
+				"totalItems": float64(3),
+				"first":      inbox + "?page=true",
+			},
+		},
+		{
+			name:  "first page",
+			query: "?page=true",
+			expect: map[string]interface{}{
+				"id":           inbox + "?page=true",
+				"type":         "OrderedCollectionPage",
+				"partOf":       inbox,
+				"orderedItems": []interface{}{"https://example.org/activities/0", "https://example.org/activities/1"},
+				"next":         inbox + "?cursor=2&page=true",
+			},
+		},
+		{
+			name:  "last page",
+			query: "?page=true&cursor=2",
+			expect: map[string]interface{}{
+				"id":           inbox + "?cursor=2&page=true",
+				"type":         "OrderedCollectionPage",
+				"partOf":       inbox,
+				"orderedItems": "https://example.org/activities/2",
+				"prev":         inbox + "?cursor=0&page=true",
+			},
+		},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/users/alice/inbox"+test.query, nil)
+		r.Host = "internal:8080"
+		r.Header.Set(acceptHeader, activityJSONMediaType)
+		w := httptest.NewRecorder()
+		if handled, err := a.GetInbox(context.Background(), w, r); !handled || err != nil {
+			t.Fatalf("(%q): expected handled without error, got %v and %v", test.name, handled, err)
+		} else if w.Code != http.StatusOK {
+			t.Fatalf("(%q): expected %v, got %v", test.name, http.StatusOK, w.Code)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		delete(m, "@context")
+		if !reflect.DeepEqual(m, test.expect) {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expect, m)
+		}
+	}
+}
+
+func TestGetPagedInboxPage(t *testing.T) {
+	inbox, err := url.Parse("https://example.net/users/alice/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := pagingDatabase{inbox: inbox}
+	for _, i := range []int{0, 1, 0} {
+		u, err := url.Parse("https://example.org/activities/" + strconv.Itoa(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.items = append(db.items, u)
+	}
+	a, err := NewFederatingActor(permissiveBehavior{}, nil, db, fixedClock(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		query string
+		code  int
+		items interface{}
+	}{
+		{
+			name:  "duplicates",
+			query: "?page=true",
+			code:  http.StatusOK,
+			items: []interface{}{"https://example.org/activities/0", "https://example.org/activities/1"},
+		},
+		{
+			name:  "invalid cursor",
+			query: "?page=true&cursor=x",
+			code:  http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/users/alice/inbox"+test.query, nil)
+		r.Header.Set(acceptHeader, activityJSONMediaType)
+		w := httptest.NewRecorder()
+		if handled, err := a.GetInbox(context.Background(), w, r); !handled || err != nil {
+			t.Fatalf("(%q): expected handled without error, got %v and %v", test.name, handled, err)
+		} else if w.Code != test.code {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.code, w.Code)
+		} else if test.items == nil {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		} else if !reflect.DeepEqual(m["orderedItems"], test.items) {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.items, m["orderedItems"])
+		}
+	}
+}
+
+func TestPageIRI(t *testing.T) {
+	tests := []struct {
+		name       string
+		collection string
+		cursor     string
+		expected   string
+	}{
+		{
+			name:       "first page",
+			collection: "https://example.net/users/alice/inbox",
+			expected:   "https://example.net/users/alice/inbox?page=true",
+		},
+		{
+			name:       "page at cursor",
+			collection: "https://example.net/users/alice/inbox",
+			cursor:     "5",
+			expected:   "https://example.net/users/alice/inbox?cursor=5&page=true",
+		},
+		{
+			name:       "collection with a query",
+			collection: "https://example.net/inbox?user=alice",
+			cursor:     "5",
+			expected:   "https://example.net/inbox?cursor=5&page=true&user=alice",
+		},
+	}
+	for _, test := range tests {
+		u, err := url.Parse(test.collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := pageIRI(u, test.cursor).String(); actual != test.expected {
+			t.Fatalf("(%q): expected %s, got %s", test.name, test.expected, actual)
+		}
+	}
+}
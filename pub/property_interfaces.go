@@ -42,6 +42,11 @@ type orderedItemser interface {
 	SetActivityStreamsOrderedItems(vocab.ActivityStreamsOrderedItemsProperty)
 }
 
+// publicKeyer is an actor with a 'publicKey' property
+type publicKeyer interface {
+	GetW3IDSecurityV1PublicKey() vocab.W3IDSecurityV1PublicKeyProperty
+}
+
 // publisheder is an ActivityStreams type with a 'published' property
 type publisheder interface {
 	GetActivityStreamsPublished() vocab.ActivityStreamsPublishedProperty
@@ -82,6 +87,12 @@ type audiencer interface {
 	SetActivityStreamsAudience(i vocab.ActivityStreamsAudienceProperty)
 }
 
+// unknowner is an ActivityStreams type with properties outside of its
+// vocabulary
+type unknowner interface {
+	GetUnknownProperties() map[string]interface{}
+}
+
 // inboxer is an ActivityStreams type with a 'inbox' property
 type inboxer interface {
 	GetActivityStreamsInbox() vocab.ActivityStreamsInboxProperty
@@ -115,3 +126,13 @@ type actorer interface {
 type appendIRIer interface {
 	AppendIRI(v *url.URL)
 }
+
+// firster is an ActivityStreams type with a 'first' property
+type firster interface {
+	GetActivityStreamsFirst() vocab.ActivityStreamsFirstProperty
+}
+
+// nexter is an ActivityStreams type with a 'next' property
+type nexter interface {
+	GetActivityStreamsNext() vocab.ActivityStreamsNextProperty
+}
@@ -0,0 +1,109 @@
+package pub
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-fed/httpsig"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReplayStore remembers the signed requests accepted by an inbox, so that a
+// captured request cannot be accepted a second time.
+type ReplayStore interface {
+	// Seen records the key until it expires, and returns true if it was
+	// already recorded and had not yet expired.
+	Seen(c context.Context, key string, expires time.Time) (bool, error)
+}
+
+// memoryReplayStore is a ReplayStore holding a bounded number of keys in
+// memory.
+type memoryReplayStore struct {
+	mu       sync.Mutex
+	clock    Clock
+	capacity int
+	expires  map[string]time.Time
+	// order holds keys in the order they were recorded, so the oldest
+	// is evicted first.
+	order []string
+}
+
+// NewMemoryReplayStore returns a ReplayStore that holds up to capacity keys in
+// memory. When full, the oldest key is forgotten to make room for a new one.
+//
+// It is suitable for a single process. Applications running several processes
+// behind a load balancer should share a ReplayStore between them instead.
+func NewMemoryReplayStore(capacity int, clock Clock) ReplayStore {
+	return &memoryReplayStore{
+		clock:    clock,
+		capacity: capacity,
+		expires:  make(map[string]time.Time, capacity),
+	}
+}
+
+// Seen records the key. When full, every expired key is evicted before the
+// oldest key is.
+func (m *memoryReplayStore) Seen(c context.Context, key string, expires time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	if e, ok := m.expires[key]; ok {
+		if now.Before(e) {
+			return true, nil
+		}
+		// Reuse the expired entry rather than recording it twice.
+		m.expires[key] = expires
+		return false, nil
+	}
+	if len(m.order) >= m.capacity {
+		m.evictExpired(now)
+	}
+	for len(m.order) > 0 && len(m.order) >= m.capacity {
+		delete(m.expires, m.order[0])
+		m.order = m.order[1:]
+	}
+	m.expires[key] = expires
+	m.order = append(m.order, key)
+	return false, nil
+}
+
+// evictExpired forgets the keys that have expired, wherever they are in the
+// order they were recorded.
+func (m *memoryReplayStore) evictExpired(now time.Time) {
+	kept := m.order[:0]
+	for _, k := range m.order {
+		if now.Before(m.expires[k]) {
+			kept = append(kept, k)
+		} else {
+			delete(m.expires, k)
+		}
+	}
+	m.order = kept
+}
+
+// checkDate ensures the Date header of the request is within the skew of the
+// current time.
+func checkDate(r *http.Request, clock Clock, skew time.Duration) (time.Time, error) {
+	date, err := http.ParseTime(r.Header.Get(dateHeader))
+	if err != nil {
+		return date, fmt.Errorf("missing or malformed %s header: %s", dateHeader, err)
+	}
+	now := clock.Now()
+	if date.Before(now.Add(-skew)) || date.After(now.Add(skew)) {
+		return date, fmt.Errorf("%s header %s is not within %s of %s", dateHeader, date, skew, now)
+	}
+	return date, nil
+}
+
+// replayKey identifies a signed request by its keyId, host, request target,
+// date, and body. The same activity delivered to two inboxes therefore has two
+// keys.
+func replayKey(r *http.Request, date time.Time, body []byte) string {
+	var keyId string
+	if v, err := httpsig.NewVerifier(r); err == nil {
+		keyId = v.KeyId()
+	}
+	return fmt.Sprintf("%s\n%s\n%s %s\n%d\n%s", keyId, r.Host, strings.ToLower(r.Method), r.URL.RequestURI(), date.Unix(), digest(body))
+}
@@ -0,0 +1,167 @@
+package pub
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMemoryReplayStore(t *testing.T) {
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	later := now.Add(time.Minute)
+	tests := []struct {
+		name     string
+		seen     []string
+		key      string
+		expected bool
+	}{
+		{
+			name:     "new key",
+			seen:     []string{"a"},
+			key:      "b",
+			expected: false,
+		},
+		{
+			name:     "repeated key",
+			seen:     []string{"a", "b"},
+			key:      "a",
+			expected: true,
+		},
+		{
+			name:     "evicted key",
+			seen:     []string{"a", "b", "c"},
+			key:      "a",
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		s := NewMemoryReplayStore(2, fixedClock(now))
+		for _, k := range test.seen {
+			if _, err := s.Seen(context.Background(), k, later); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if actual, err := s.Seen(context.Background(), test.key, later); err != nil {
+			t.Fatal(err)
+		} else if actual != test.expected {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestMemoryReplayStoreExpiry(t *testing.T) {
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := NewMemoryReplayStore(2, fixedClock(now))
+	if _, err := s.Seen(context.Background(), "a", now.Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if seen, err := s.Seen(context.Background(), "a", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	} else if seen {
+		t.Fatalf("expected expired key to not be seen")
+	}
+}
+
+func TestMemoryReplayStoreEvictsExpiredFirst(t *testing.T) {
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := NewMemoryReplayStore(2, fixedClock(now))
+	for _, k := range []struct {
+		key     string
+		expires time.Time
+	}{
+		{"a", now.Add(time.Minute)},
+		{"b", now.Add(-time.Second)},
+		{"c", now.Add(time.Minute)},
+	} {
+		if _, err := s.Seen(context.Background(), k.key, k.expires); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if seen, err := s.Seen(context.Background(), "a", now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	} else if !seen {
+		t.Fatalf("expected the oldest unexpired key to be kept")
+	}
+}
+
+func TestReplayKey(t *testing.T) {
+	date := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	body := []byte(`{"type":"Like"}`)
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected bool
+	}{
+		{
+			name:     "same inbox",
+			a:        "https://example.net/users/bob/inbox",
+			b:        "https://example.net/users/bob/inbox",
+			expected: true,
+		},
+		{
+			name:     "other inbox",
+			a:        "https://example.net/users/bob/inbox",
+			b:        "https://example.net/users/alice/inbox",
+			expected: false,
+		},
+		{
+			name:     "other host",
+			a:        "https://example.net/users/bob/inbox",
+			b:        "https://example.org/users/bob/inbox",
+			expected: false,
+		},
+	}
+	for _, test := range tests {
+		a := httptest.NewRequest("POST", test.a, nil)
+		b := httptest.NewRequest("POST", test.b, nil)
+		if actual := replayKey(a, date, body) == replayKey(b, date, body); actual != test.expected {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestCheckDate(t *testing.T) {
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		date    string
+		wantErr bool
+	}{
+		{
+			name: "current",
+			date: "Wed, 02 Jan 2019 03:04:05 GMT",
+		},
+		{
+			name: "within skew",
+			date: "Wed, 02 Jan 2019 03:00:05 GMT",
+		},
+		{
+			name:    "stale",
+			date:    "Wed, 02 Jan 2019 02:04:05 GMT",
+			wantErr: true,
+		},
+		{
+			name:    "future",
+			date:    "Wed, 02 Jan 2019 04:04:05 GMT",
+			wantErr: true,
+		},
+		{
+			name:    "missing",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "https://example.net/users/bob/inbox", nil)
+		if len(test.date) > 0 {
+			r.Header.Set(dateHeader, test.date)
+		}
+		_, err := checkDate(r, fixedClock(now), 5*time.Minute)
+		if test.wantErr && err == nil {
+			t.Fatalf("(%q): expected error, got none", test.name)
+		} else if !test.wantErr && err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package pub
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// requesterKey is the context key for the IRI of the actor that made a
+// request.
+type requesterKey struct{}
+
+// WithRequester returns a copy of the context that carries the IRI of the
+// actor whose identity was verified on the request being handled.
+func WithRequester(c context.Context, actorIRI *url.URL) context.Context {
+	return context.WithValue(c, requesterKey{}, actorIRI)
+}
+
+// SetRequester records on the request the IRI of the actor whose identity was
+// verified by authenticating it. A FederatingProtocol's AuthenticatePostInbox
+// calls it once the request is authentic, so that the Actor sets the requester
+// in the context of the activity's side effects.
+func SetRequester(r *http.Request, actorIRI *url.URL) {
+	*r = *r.WithContext(WithRequester(r.Context(), actorIRI))
+}
+
+// RequesterFromContext returns the IRI of the actor whose identity was verified
+// on the request being handled, or nil if there is no verified requester.
+//
+// Actors created WithAuthorizedFetch, and the HandlerFunc returned by
+// NewAuthorizedFetchHandler, set the requester before calling into the
+// application.
+func RequesterFromContext(c context.Context) *url.URL {
+	actorIRI, _ := c.Value(requesterKey{}).(*url.URL)
+	return actorIRI
+}
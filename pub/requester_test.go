@@ -0,0 +1,153 @@
+package pub
+
+import (
+	"context"
+	"crypto"
+	"github.com/go-fed/activity/streams"
+	"github.com/go-fed/activity/streams/vocab"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// requesterBehavior permits every GET, recording the requester in the context.
+type requesterBehavior struct {
+	CommonBehavior
+	requester *url.URL
+}
+
+func (b *requesterBehavior) AuthenticateGetInbox(c context.Context, w http.ResponseWriter, r *http.Request) (bool, error) {
+	b.requester = RequesterFromContext(c)
+	return false, nil
+}
+
+// noteDatabase serves a single Note.
+type noteDatabase struct {
+	Database
+}
+
+func (noteDatabase) Lock(c context.Context, id *url.URL) error   { return nil }
+func (noteDatabase) Unlock(c context.Context, id *url.URL) error { return nil }
+
+func (noteDatabase) Get(c context.Context, id *url.URL) (vocab.Type, error) {
+	note := streams.NewActivityStreamsNote()
+	idProp := streams.NewActivityStreamsIdProperty()
+	idProp.Set(id)
+	note.SetActivityStreamsId(idProp)
+	return note, nil
+}
+
+func TestRequesterFromContext(t *testing.T) {
+	u, err := url.Parse(testKeyOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := RequesterFromContext(context.Background()); actual != nil {
+		t.Fatalf("expected no requester, got %v", actual)
+	} else if actual := RequesterFromContext(WithRequester(context.Background(), u)); actual != u {
+		t.Fatalf("expected %v, got %v", u, actual)
+	}
+}
+
+// authorizedFetchTests are the requests made to inboxes and handlers requiring
+// authorized fetch.
+var authorizedFetchTests = []struct {
+	name            string
+	sign            bool
+	otherKey        bool
+	expectCode      int
+	expectRequester string
+}{
+	{
+		name:       "unsigned",
+		expectCode: http.StatusUnauthorized,
+	},
+	{
+		name:       "bad signature",
+		sign:       true,
+		otherKey:   true,
+		expectCode: http.StatusUnauthorized,
+	},
+	{
+		name:            "valid",
+		sign:            true,
+		expectCode:      http.StatusOK,
+		expectRequester: testKeyOwner,
+	},
+}
+
+// newAuthorizedFetchRequest creates a GET request for the IRI, signed with the
+// private key unless it is nil.
+func newAuthorizedFetchRequest(t *testing.T, iri string, privKey crypto.PrivateKey) *http.Request {
+	r := httptest.NewRequest("GET", iri, nil)
+	r.Header.Set(acceptHeader, activityJSONMediaType)
+	r.Header.Set(dateHeader, "Mon, 02 Jan 2006 15:04:05 GMT")
+	if privKey != nil {
+		signTestRequest(t, r, nil, privKey, testKeyId)
+	}
+	return r
+}
+
+// authorizedFetchKey returns the key to sign the test's request with.
+func authorizedFetchKey(sign, otherKey bool, privKey, otherPrivKey crypto.PrivateKey) crypto.PrivateKey {
+	if !sign {
+		return nil
+	} else if otherKey {
+		return otherPrivKey
+	}
+	return privKey
+}
+
+func TestAuthorizedFetchGetInbox(t *testing.T) {
+	privKey, pubPem := newTestKeyPair(t)
+	otherPrivKey, _ := newTestKeyPair(t)
+	inbox, err := url.Parse("https://example.net/users/bob/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range authorizedFetchTests {
+		v := NewHttpSigAuthenticator(func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+			return fixedTransport{doc: testActorDoc(testKeyOwner, pubPem)}, nil
+		})
+		b := &requesterBehavior{}
+		a, err := NewFederatingActor(b, nil, pagingDatabase{inbox: inbox}, fixedClock(time.Now()), WithAuthorizedFetch(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := newAuthorizedFetchRequest(t, "https://example.net/users/bob/inbox", authorizedFetchKey(test.sign, test.otherKey, privKey, otherPrivKey))
+		w := httptest.NewRecorder()
+		if handled, err := a.GetInbox(context.Background(), w, r); !handled || err != nil {
+			t.Fatalf("(%q): expected handled without error, got %v and %v", test.name, handled, err)
+		} else if w.Code != test.expectCode {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expectCode, w.Code)
+		} else if test.expectCode == http.StatusOK && (b.requester == nil || b.requester.String() != test.expectRequester) {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expectRequester, b.requester)
+		}
+	}
+}
+
+func TestAuthorizedFetchHandler(t *testing.T) {
+	privKey, pubPem := newTestKeyPair(t)
+	otherPrivKey, _ := newTestKeyPair(t)
+	for _, test := range authorizedFetchTests {
+		v := NewHttpSigAuthenticator(func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+			return fixedTransport{doc: testActorDoc(testKeyOwner, pubPem)}, nil
+		})
+		var requester *url.URL
+		h := NewAuthorizedFetchHandler(v, func(c context.Context, w http.ResponseWriter, r *http.Request) (bool, error) {
+			requester = RequesterFromContext(c)
+			return false, nil
+		}, noteDatabase{}, fixedClock(time.Now()))
+		r := newAuthorizedFetchRequest(t, "https://example.net/notes/1", authorizedFetchKey(test.sign, test.otherKey, privKey, otherPrivKey))
+		w := httptest.NewRecorder()
+		if isASRequest, err := h(context.Background(), w, r); !isASRequest || err != nil {
+			t.Fatalf("(%q): expected handled without error, got %v and %v", test.name, isASRequest, err)
+		} else if w.Code != test.expectCode {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expectCode, w.Code)
+		} else if test.expectCode == http.StatusOK && (requester == nil || requester.String() != test.expectRequester) {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expectRequester, requester)
+		}
+	}
+}
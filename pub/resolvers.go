@@ -2,19 +2,26 @@ package pub
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/go-fed/activity/streams"
 	"github.com/go-fed/activity/streams/vocab"
 )
 
 // IsAnActivityType returns true if the ActivityStreams value is an Activity or
-// extends from the Activity type.
+// extends from the Activity type. An UnknownActivity is considered to be one.
 func IsAnActivityType(value vocab.Type) bool {
+	if _, ok := value.(*UnknownActivity); ok {
+		return true
+	}
 	return value.GetName() == "Activity" || streams.ActivityStreamsActivityIsExtendedBy(value)
 }
 
 // toAsType converts a generic map[string]interface{} into a known Type.
 //
-// Returns errors under the same conditions as streams.JSONResolver does.
+// Activities of types unknown to go-fed are converted into an UnknownActivity.
+// Otherwise, returns errors under the same conditions as streams.JSONResolver
+// does.
 func toType(c context.Context, m map[string]interface{}) (a vocab.Type, e error) {
 	var r *streams.JSONResolver
 	// Every time new types are added, need to update this list. It looks
@@ -238,14 +245,40 @@ func toType(c context.Context, m map[string]interface{}) (a vocab.Type, e error)
 			a = i
 			return nil
 		},
+		func(ctx context.Context, i vocab.W3IDSecurityV1PublicKey) error {
+			a = i
+			return nil
+		},
 	)
 	if e != nil {
 		return
 	}
 	e = r.Resolve(c, m)
+	if e == streams.ErrUnhandledType {
+		if u, err := toUnknownActivity(c, m); err == nil {
+			a, e = u, nil
+		}
+	}
 	return
 }
 
+// toActivity converts the JSON of an activity into an Activity.
+func toActivity(c context.Context, raw []byte) (Activity, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	asValue, err := toType(c, m)
+	if err != nil {
+		return nil, err
+	}
+	activity, ok := asValue.(Activity)
+	if !ok {
+		return nil, fmt.Errorf("activity streams value is not an Activity: %T", asValue)
+	}
+	return activity, nil
+}
+
 // addToCreate adds the object to the Create activity.
 func addToCreate(ctx context.Context, c vocab.ActivityStreamsCreate, o vocab.Type) error {
 	obj := c.GetActivityStreamsObject()
@@ -473,6 +506,10 @@ func addToCreate(ctx context.Context, c vocab.ActivityStreamsCreate, o vocab.Typ
 			obj.AppendActivityStreamsView(v)
 			return nil
 		},
+		func(ctx context.Context, v vocab.W3IDSecurityV1PublicKey) error {
+			obj.AppendW3IDSecurityV1PublicKey(v)
+			return nil
+		},
 	)
 	if e != nil {
 		return e
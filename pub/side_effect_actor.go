@@ -14,6 +14,9 @@ import (
 // sideEffectActor must satisfy the DelegateActor interface.
 var _ DelegateActor = &sideEffectActor{}
 
+// sideEffectActor must satisfy the SharedInboxDelegate interface.
+var _ SharedInboxDelegate = &sideEffectActor{}
+
 // sideEffectActor is a DelegateActor that handles the ActivityPub
 // implementation side effects, but requires a more opinionated application to
 // be written.
@@ -27,6 +30,14 @@ type sideEffectActor struct {
 	c2s    SocialProtocol
 	db     Database
 	clock  Clock
+	// ldSigner, if set, signs the activities delivered from an outbox.
+	ldSigner *LDSigner
+	// deliverer, if set, schedules deliveries instead of sending them
+	// before the request is answered.
+	deliverer Deliverer
+	// pageSize is the number of items on each page of a paged inbox or
+	// outbox.
+	pageSize int
 }
 
 // AuthenticatePostInbox defers to the delegate to authenticate the request.
@@ -102,14 +113,18 @@ func (a *sideEffectActor) PostInbox(c context.Context, inboxIRI *url.URL, activi
 		wrapped.db = a.db
 		wrapped.inboxIRI = inboxIRI
 		wrapped.newTransport = a.s2s.NewTransport
+		wrapped.deliverer = a.deliverer
 		if err = wrapped.disjoint(other); err != nil {
 			return err
 		}
-		res, err := streams.NewTypeResolver(append(wrapped.callbacks(), other...))
+		res, err := streams.NewTypeResolver(append(wrapped.callbacks(), other...)...)
 		if err != nil {
 			return err
 		}
-		if err = res.Resolve(c, activity); err != nil {
+		if err = res.Resolve(c, activity); streams.IsUnmatchedErr(err) {
+			err = wrapped.defaultFn(c, activity)
+		}
+		if err != nil {
 			return err
 		}
 	}
@@ -139,11 +154,18 @@ func (a *sideEffectActor) InboxForwarding(c context.Context, inboxIRI *url.URL,
 	} else if exists {
 		return nil
 	}
-	// Attempt to create the activity entry.
-	err = a.db.Create(c, activity)
+	// Attempt to create the activity entry, as checked against the origin
+	// policy by the side effects.
+	checked, asReceived := checkedActivity(c, activity)
+	err = a.db.Create(c, checked)
 	if err != nil {
 		return err
 	}
+	// Do not forward objects embedded by a peer on another's behalf,
+	// since the side effects used a checked copy of them instead.
+	if !asReceived {
+		return nil
+	}
 	// 2. The values of 'to', 'cc', or 'audience' are Collections owned by
 	//    this server.
 	var r []*url.URL
@@ -267,7 +289,84 @@ func (a *sideEffectActor) InboxForwarding(c context.Context, inboxIRI *url.URL,
 			}
 		}
 	}
-	return a.deliverToRecipients(c, inboxIRI, activity, recipients)
+	m, err := serialize(activity)
+	if err != nil {
+		return err
+	}
+	return a.deliverToRecipients(c, inboxIRI, m, recipients)
+}
+
+// SharedInboxRecipients determines the inboxes of the local actors that an
+// activity received by the shared inbox is for.
+//
+// These are the local actors it addresses. If it is addressed to the Public
+// collection or to a collection this server does not own, such as the
+// followers of its actor, then the local followers of its actors are included
+// when the Database is a LocalFollowersDatabase.
+func (a *sideEffectActor) SharedInboxRecipients(c context.Context, activity Activity) (inboxes []*url.URL, err error) {
+	recipients, err := getRecipients(activity)
+	if err != nil {
+		return
+	}
+	var local []*url.URL
+	toFollowers := false
+	for _, iri := range recipients {
+		if IsPublic(iri.String()) {
+			toFollowers = true
+			continue
+		}
+		var owns bool
+		if owns, err = a.db.Owns(c, iri); err != nil {
+			return
+		} else if owns {
+			local = append(local, iri)
+		} else {
+			toFollowers = true
+		}
+	}
+	if fdb, ok := a.db.(LocalFollowersDatabase); ok && toFollowers {
+		actors := activity.GetActivityStreamsActor()
+		if actors != nil {
+			for iter := actors.Begin(); iter != actors.End(); iter = iter.Next() {
+				var actorIRI *url.URL
+				if actorIRI, err = ToId(iter); err != nil {
+					return
+				}
+				var followers []*url.URL
+				if followers, err = fdb.LocalFollowers(c, actorIRI); err != nil {
+					return
+				}
+				local = append(local, followers...)
+			}
+		}
+	}
+	for _, iri := range dedupeIRIs(local, nil) {
+		// Local addressees may not exist, or be collections rather
+		// than actors, in which case they have no inbox to post to.
+		err = a.db.Lock(c, iri)
+		if err != nil {
+			return
+		}
+		var exists bool
+		exists, err = a.db.Exists(c, iri)
+		if err != nil {
+			a.db.Unlock(c, iri)
+			return
+		} else if !exists {
+			a.db.Unlock(c, iri)
+			continue
+		}
+		var t vocab.Type
+		t, err = a.db.Get(c, iri)
+		a.db.Unlock(c, iri)
+		if err != nil {
+			return
+		}
+		if inbox, err := getInbox(t); err == nil {
+			inboxes = append(inboxes, inbox)
+		}
+	}
+	return
 }
 
 // PostOutbox handles the side effects of adding the activity to the actor's
@@ -286,14 +385,19 @@ func (a *sideEffectActor) PostOutbox(c context.Context, activity Activity, outbo
 	if e = wrapped.disjoint(other); e != nil {
 		return
 	}
-	res, err := streams.NewTypeResolver(append(wrapped.callbacks(), other...))
+	res, err := streams.NewTypeResolver(append(wrapped.callbacks(), other...)...)
 	if err != nil {
+		e = err
 		return
 	}
-	if err = res.Resolve(c, activity); err != nil {
+	if err = res.Resolve(c, activity); streams.IsUnmatchedErr(err) {
+		err = wrapped.defaultFn(c, activity)
+	}
+	if err != nil {
+		e = err
 		return
 	}
-	err = a.addToOutbox(c, outboxIRI, activity)
+	e = a.addToOutbox(c, outboxIRI, activity)
 	return
 }
 
@@ -335,11 +439,77 @@ func (a *sideEffectActor) AddNewIds(c context.Context, activity Activity) error
 //
 // Must only be called if both social and federated protocols are supported.
 func (a *sideEffectActor) Deliver(c context.Context, outboxIRI *url.URL, activity Activity) error {
-	recipients, err := a.prepare(c, outboxIRI, activity)
+	recipients, local, err := a.prepare(c, outboxIRI, activity)
+	if err != nil {
+		return err
+	}
+	m, err := serialize(activity)
+	if err != nil {
+		return err
+	}
+	if len(local) > 0 {
+		// Local inboxes receive the activity from the outbox's actor.
+		//
+		// TODO: Acquire a lock.
+		actorIRI, err := a.db.ActorForOutbox(c, outboxIRI)
+		if err != nil {
+			return err
+		}
+		if err = a.deliverLocally(WithRequester(c, actorIRI), m, local); err != nil {
+			return err
+		}
+	}
+	if a.ldSigner != nil {
+		if err = a.ldSigner.Sign(c, m); err != nil {
+			return err
+		}
+	}
+	return a.deliverToRecipients(c, outboxIRI, m, recipients)
+}
+
+// deliverLocally posts a serialized Activity directly to the inboxes of actors
+// on this server, triggering the same side effects as if it had been
+// federated to them, without making any requests.
+//
+// Each inbox receives its own copy of the Activity, which is not delivered if
+// its actors are blocked. The Deliverer is not used, as it may persist the
+// deliveries and replay them over HTTP.
+func (a *sideEffectActor) deliverLocally(c context.Context, m map[string]interface{}, inboxes []*url.URL) error {
+	for _, inboxIRI := range inboxes {
+		if err := a.postLocally(c, m, inboxIRI); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postLocally posts a copy of the serialized Activity to a local inbox, unless
+// its actors are blocked.
+func (a *sideEffectActor) postLocally(c context.Context, m map[string]interface{}, inboxIRI *url.URL) error {
+	t, err := toType(c, m)
 	if err != nil {
 		return err
 	}
-	return a.deliverToRecipients(c, outboxIRI, activity, recipients)
+	activity, ok := t.(Activity)
+	if !ok {
+		return fmt.Errorf("activity streams value is not an Activity: %T", t)
+	}
+	var actors []*url.URL
+	if ap := activity.GetActivityStreamsActor(); ap != nil {
+		for iter := ap.Begin(); iter != ap.End(); iter = iter.Next() {
+			id, err := ToId(iter)
+			if err != nil {
+				return err
+			}
+			actors = append(actors, id)
+		}
+	}
+	if blocked, err := a.s2s.Blocked(c, actors); err != nil {
+		return err
+	} else if blocked {
+		return nil
+	}
+	return a.PostInbox(c, inboxIRI, activity)
 }
 
 // WrapInCreate wraps an object with a Create activity.
@@ -352,17 +522,17 @@ func (a *sideEffectActor) WrapInCreate(c context.Context, obj vocab.Type, outbox
 	return wrapInCreate(c, obj, actorIri)
 }
 
-// deliverToRecipients will take a prepared Activity and send it to specific
-// recipients on behalf of an actor.
-func (a *sideEffectActor) deliverToRecipients(c context.Context, boxIRI *url.URL, activity Activity, recipients []*url.URL) error {
-	m, err := serialize(activity)
-	if err != nil {
-		return err
-	}
+// deliverToRecipients will take a prepared and serialized Activity and send it
+// to specific recipients on behalf of an actor.
+func (a *sideEffectActor) deliverToRecipients(c context.Context, boxIRI *url.URL, m map[string]interface{}, recipients []*url.URL) error {
 	b, err := json.Marshal(m)
 	if err != nil {
 		return err
 	}
+	if a.deliverer != nil {
+		scheduleDeliveries(a.deliverer, a.s2s.NewTransport, boxIRI, b, recipients)
+		return nil
+	}
 	tp, err := a.s2s.NewTransport(c, boxIRI, goFedUserAgent())
 	if err != nil {
 		return err
@@ -403,6 +573,10 @@ func (a *sideEffectActor) addToOutbox(c context.Context, outboxIRI *url.URL, act
 	// WARNING: Unlock(c, id) should be called by this point and in every
 	// return before here.
 
+	// If possible, add the activity without rewriting the outbox.
+	if oa, ok := a.db.(OutboxAppender); ok {
+		return oa.AppendToOutbox(c, outboxIRI, id.Get())
+	}
 	// Acquire a lock to read the outbox. Defer release.
 	err = a.db.Lock(c, outboxIRI)
 	if err != nil {
@@ -432,6 +606,10 @@ func (a *sideEffectActor) addToOutbox(c context.Context, outboxIRI *url.URL, act
 //
 // Returns true when the activity is novel.
 func (a *sideEffectActor) addToInboxIfNew(c context.Context, inboxIRI *url.URL, activity Activity) (isNew bool, err error) {
+	// If possible, add the activity without rewriting the inbox.
+	if ia, ok := a.db.(InboxAppender); ok {
+		return ia.AppendToInbox(c, inboxIRI, activity.GetActivityStreamsId().Get())
+	}
 	// Acquire a lock to read the inbox. Defer release.
 	err = a.db.Lock(c, inboxIRI)
 	if err != nil {
@@ -510,120 +688,94 @@ func (a *sideEffectActor) hasInboxForwardingValues(c context.Context, val vocab.
 }
 
 // prepare takes a deliverableObject and returns a list of the proper recipient
-// target URIs. Additionally, the deliverableObject will have any hidden
-// hidden recipients ("bto" and "bcc") stripped from it.
+// target URIs, separating the inboxes of actors on this server from those of
+// peers. Additionally, the deliverableObject will have any hidden hidden
+// recipients ("bto" and "bcc") stripped from it.
 //
 // Only call if both the social and federated protocol are supported.
-func (a *sideEffectActor) prepare(c context.Context, outboxIRI *url.URL, activity Activity) (r []*url.URL, err error) {
+func (a *sideEffectActor) prepare(c context.Context, outboxIRI *url.URL, activity Activity) (r, local []*url.URL, err error) {
 	// Get inboxes of recipients
-	if to := activity.GetActivityStreamsTo(); to != nil {
-		for iter := to.Begin(); iter != to.End(); iter = iter.Next() {
-			var val *url.URL
-			val, err = ToId(iter)
-			if err != nil {
-				return
-			}
-			r = append(r, val)
-		}
-	}
-	if bto := activity.GetActivityStreamsBto(); bto != nil {
-		for iter := bto.Begin(); iter != bto.End(); iter = iter.Next() {
-			var val *url.URL
-			val, err = ToId(iter)
-			if err != nil {
-				return
-			}
-			r = append(r, val)
-		}
-	}
-	if cc := activity.GetActivityStreamsCc(); cc != nil {
-		for iter := cc.Begin(); iter != cc.End(); iter = iter.Next() {
-			var val *url.URL
-			val, err = ToId(iter)
-			if err != nil {
-				return
-			}
-			r = append(r, val)
-		}
-	}
-	if bcc := activity.GetActivityStreamsBcc(); bcc != nil {
-		for iter := bcc.Begin(); iter != bcc.End(); iter = iter.Next() {
-			var val *url.URL
-			val, err = ToId(iter)
-			if err != nil {
-				return
-			}
-			r = append(r, val)
-		}
+	r, err = getRecipients(activity)
+	if err != nil {
+		return
 	}
-	if audience := activity.GetActivityStreamsAudience(); audience != nil {
-		for iter := audience.Begin(); iter != audience.End(); iter = iter.Next() {
-			var val *url.URL
-			val, err = ToId(iter)
-			if err != nil {
-				return
-			}
-			r = append(r, val)
-		}
-	}
-	// TODO: Support delivery to shared inbox
-	// 1. When an object is being delivered to the originating actor's
-	//    followers, a server MAY reduce the number of receiving actors
-	//    delivered to by identifying all followers which share the same
-	//    sharedInbox who would otherwise be individual recipients and
-	//    instead deliver objects to said sharedInbox.
-	// 2. If an object is addressed to the Public special collection, a
-	//    server MAY deliver that object to all known sharedInbox endpoints
-	//    on the network.
+	// Recipients sharing the same sharedInbox are delivered to once, as
+	// getInboxes prefers it over their individual inboxes.
+	//
+	// TODO: If an object is addressed to the Public special collection, a
+	// server MAY deliver that object to all known sharedInbox endpoints on
+	// the network.
 	r = filterURLs(r, IsPublic)
 	t, err := a.s2s.NewTransport(c, outboxIRI, goFedUserAgent())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	receiverActors, err := a.resolveInboxes(c, t, r, 0, a.s2s.MaxDeliveryRecursionDepth(c))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	// Local actors are delivered to at their own inbox, never a shared one,
+	// as their inbox is in the database.
+	var remoteActors []vocab.Type
+	var localTargets []*url.URL
+	for _, actor := range receiverActors {
+		var owns bool
+		if id, err := GetId(actor); err == nil {
+			if owns, err = a.db.Owns(c, id); err != nil {
+				return nil, nil, err
+			}
+		}
+		if !owns {
+			remoteActors = append(remoteActors, actor)
+			continue
+		}
+		inbox, err := getInbox(actor)
+		if err != nil {
+			return nil, nil, err
+		}
+		localTargets = append(localTargets, inbox)
 	}
-	targets, err := getInboxes(receiverActors)
+	targets, err := getInboxes(remoteActors)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// Get inboxes of sender.
 	// TODO: Acquire a lock.
 	actorIRI, err := a.db.ActorForOutbox(c, outboxIRI)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// Make sure this matches the 'attributedTo' on the activity.
 	attrTo := activity.GetActivityStreamsAttributedTo()
 	if attrTo.Len() != 1 {
-		return nil, fmt.Errorf("federated c2s object does not have exactly one attributedTo value: %d", attrTo.Len())
+		return nil, nil, fmt.Errorf("federated c2s object does not have exactly one attributedTo value: %d", attrTo.Len())
 	} else if attrToIRI, err := ToId(attrTo.At(0)); err != nil {
-		return nil, err
+		return nil, nil, err
 	} else if attrToIRI.String() != actorIRI.String() {
-		return nil, fmt.Errorf("federated c2s object attributedTo value does not match this actor")
+		return nil, nil, fmt.Errorf("federated c2s object attributedTo value does not match this actor")
 	}
 	// Get the inbox on the sender.
 	err = a.db.Lock(c, actorIRI)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// BEGIN LOCK
 	thisActor, err := a.db.Get(c, actorIRI)
 	a.db.Unlock(c, actorIRI)
 	// END LOCK -- Still need to handle err
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// Post-processing
 	var ignore *url.URL
 	ignore, err = getInbox(thisActor)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	r = dedupeIRIs(targets, []*url.URL{ignore})
+	local = dedupeIRIs(localTargets, []*url.URL{ignore})
 	stripHiddenRecipients(activity)
-	return r, nil
+	return r, local, nil
 }
 
 // resolveInboxes takes a list of Actor id URIs and returns them as concrete
@@ -635,8 +787,9 @@ func (a *sideEffectActor) prepare(c context.Context, outboxIRI *url.URL, activit
 // If a recipient is a Collection or OrderedCollection, then the server MUST
 // dereference the collection, WITH the user's credentials.
 //
-// Note that this also applies to CollectionPage and OrderedCollectionPage.
-// TODO: Handle Page types by paginating.
+// Note that this also applies to CollectionPage and OrderedCollectionPage. The
+// pages of a collection are walked, but the collection itself is not one of the
+// returned actors.
 func (a *sideEffectActor) resolveInboxes(c context.Context, t Transport, r []*url.URL, depth, maxDepth int) (actors []vocab.Type, err error) {
 	if maxDepth > 0 && depth >= maxDepth {
 		return
@@ -653,7 +806,9 @@ func (a *sideEffectActor) resolveInboxes(c context.Context, t Transport, r []*ur
 		if err != nil {
 			return
 		}
-		actors = append(actors, act)
+		if act != nil {
+			actors = append(actors, act)
+		}
 		actors = append(actors, recurActors...)
 	}
 	return
@@ -662,41 +817,43 @@ func (a *sideEffectActor) resolveInboxes(c context.Context, t Transport, r []*ur
 // dereferenceForResolvingInboxes dereferences an IRI solely for finding an
 // actor's inbox IRI to deliver to.
 func (a *sideEffectActor) dereferenceForResolvingInboxes(c context.Context, t Transport, actorIRI *url.URL) (actor vocab.Type, moreActorIRIs []*url.URL, err error) {
-	var resp []byte
-	resp, err = t.Dereference(c, actorIRI)
-	if err != nil {
-		return
+	dereference := func(c context.Context, iri *url.URL) (vocab.Type, error) {
+		return a.dereferenceOwnedOrRemote(c, t, iri)
 	}
-	var m map[string]interface{}
-	if err = json.Unmarshal(resp, &m); err != nil {
-		return
-	}
-	actor, err = toType(c, m)
+	actor, err = dereference(c, actorIRI)
 	if err != nil {
 		return
 	}
-	// Attempt to see if the 'actor' is really some sort of type that has
-	// an 'items' or 'orderedItems' property.
-	if v, ok := actor.(itemser); ok {
-		i := v.GetActivityStreamsItems()
-		for iter := i.Begin(); iter != i.End(); iter = iter.Next() {
-			var id *url.URL
-			id, err = ToId(iter)
+	// Attempt to see if the 'actor' is really some sort of collection, in
+	// which case its items, on any of its pages, are the actors.
+	if isCollection(actor) {
+		w := newCollectionWalker(dereference, defaultMaxCollectionPages)
+		err = w.walk(c, actorIRI, actor, func(item IdProperty) error {
+			id, err := ToId(item)
 			if err != nil {
-				return
-			}
-			moreActorIRIs = append(moreActorIRIs, id)
-		}
-	} else if v, ok := actor.(orderedItemser); ok {
-		i := v.GetActivityStreamsOrderedItems()
-		for iter := i.Begin(); iter != i.End(); iter = iter.Next() {
-			var id *url.URL
-			id, err = ToId(iter)
-			if err != nil {
-				return
+				return err
 			}
 			moreActorIRIs = append(moreActorIRIs, id)
-		}
+			return nil
+		})
+		actor = nil
 	}
 	return
 }
+
+// dereferenceOwnedOrRemote obtains the type at the IRI. Our own actors,
+// collections, and their pages are fetched from the database rather than
+// requested from ourselves.
+func (a *sideEffectActor) dereferenceOwnedOrRemote(c context.Context, t Transport, iri *url.URL) (vocab.Type, error) {
+	owns, err := a.db.Owns(c, iri)
+	if err != nil {
+		return nil, err
+	} else if !owns {
+		return dereferenceType(c, t, iri)
+	}
+	if err = a.db.Lock(c, iri); err != nil {
+		return nil, err
+	}
+	defer a.db.Unlock(c, iri)
+	return a.db.Get(c, iri)
+}
@@ -0,0 +1,485 @@
+package pub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-fed/activity/streams"
+	"github.com/go-fed/activity/streams/vocab"
+	"net/url"
+	"sort"
+	"testing"
+)
+
+// localActorsDatabase owns a fixed set of actors, each with an inbox, and
+// knows which of them follow which peers. If host is set, it also owns every
+// other IRI on the host, none of which exist.
+type localActorsDatabase struct {
+	Database
+	host      string
+	actors    map[string]vocab.Type
+	followers map[string][]*url.URL
+}
+
+func newLocalActorsDatabase(t *testing.T, followers map[string][]string, ids ...string) localActorsDatabase {
+	db := localActorsDatabase{
+		actors:    make(map[string]vocab.Type, len(ids)),
+		followers: make(map[string][]*url.URL, len(followers)),
+	}
+	for _, id := range ids {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(fmt.Sprintf(`{"@context":"https://www.w3.org/ns/activitystreams","id":%q,"type":"Person","inbox":%q}`, id, id+"/inbox")), &m); err != nil {
+			t.Fatal(err)
+		}
+		actor, err := toType(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.actors[id] = actor
+	}
+	for peer, fs := range followers {
+		for _, f := range fs {
+			u, err := url.Parse(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			db.followers[peer] = append(db.followers[peer], u)
+		}
+	}
+	return db
+}
+
+func (l localActorsDatabase) Lock(c context.Context, id *url.URL) error   { return nil }
+func (l localActorsDatabase) Unlock(c context.Context, id *url.URL) error { return nil }
+
+func (l localActorsDatabase) Owns(c context.Context, id *url.URL) (bool, error) {
+	_, ok := l.actors[id.String()]
+	return ok || (len(l.host) > 0 && id.Host == l.host), nil
+}
+
+func (l localActorsDatabase) Exists(c context.Context, id *url.URL) (bool, error) {
+	_, ok := l.actors[id.String()]
+	return ok, nil
+}
+
+func (l localActorsDatabase) Get(c context.Context, id *url.URL) (vocab.Type, error) {
+	t, ok := l.actors[id.String()]
+	if !ok {
+		return nil, fmt.Errorf("not found: %s", id)
+	}
+	return t, nil
+}
+
+func (l localActorsDatabase) LocalFollowers(c context.Context, actorIRI *url.URL) ([]*url.URL, error) {
+	return l.followers[actorIRI.String()], nil
+}
+
+func TestSideEffectActorSharedInboxRecipients(t *testing.T) {
+	const (
+		alice = "https://example.net/users/alice"
+		bob   = "https://example.net/users/bob"
+		carol = "https://example.net/users/carol"
+	)
+	db := newLocalActorsDatabase(t, map[string][]string{testKeyOwner: {bob, carol}}, alice, bob, carol)
+	db.host = "example.net"
+	tests := []struct {
+		name     string
+		to       string
+		expected []string
+	}{
+		{
+			name:     "addressed local actor",
+			to:       `"` + alice + `"`,
+			expected: []string{alice + "/inbox"},
+		},
+		{
+			name:     "unknown local addressee",
+			to:       `["https://example.net/users/nobody","` + alice + `"]`,
+			expected: []string{alice + "/inbox"},
+		},
+		{
+			name:     "public",
+			to:       `"https://www.w3.org/ns/activitystreams#Public"`,
+			expected: []string{bob + "/inbox", carol + "/inbox"},
+		},
+		{
+			name:     "followers and addressed local follower",
+			to:       `["https://example.com/users/alice/followers","` + bob + `"]`,
+			expected: []string{bob + "/inbox", carol + "/inbox"},
+		},
+	}
+	a := &sideEffectActor{db: db}
+	for _, test := range tests {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(fmt.Sprintf(`{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/activity/1","type":"Create","actor":%q,"to":%s,"object":"https://example.com/note/1"}`, testKeyOwner, test.to)), &m); err != nil {
+			t.Fatal(err)
+		}
+		asValue, err := toType(context.Background(), m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inboxes, err := a.SharedInboxRecipients(context.Background(), asValue.(Activity))
+		if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		actual := make([]string, 0, len(inboxes))
+		for _, inbox := range inboxes {
+			actual = append(actual, inbox.String())
+		}
+		sort.Strings(actual)
+		if fmt.Sprint(actual) != fmt.Sprint(test.expected) {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+// queueDeliverer holds scheduled deliveries until they are run.
+type queueDeliverer struct {
+	queued []func() error
+}
+
+func (q *queueDeliverer) Do(b []byte, to *url.URL, sendFn func([]byte, *url.URL) error) {
+	q.queued = append(q.queued, func() error { return sendFn(b, to) })
+}
+
+// deliveringProtocol creates Transports recording the recipients delivered to.
+type deliveringProtocol struct {
+	FederatingProtocol
+	delivered *[]string
+}
+
+func (d deliveringProtocol) NewTransport(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+	return recipientTransport{delivered: d.delivered}, nil
+}
+
+type recipientTransport struct {
+	Transport
+	delivered *[]string
+}
+
+func (r recipientTransport) Deliver(c context.Context, b []byte, to *url.URL) error {
+	*r.delivered = append(*r.delivered, to.String())
+	return nil
+}
+
+func TestSideEffectActorDeliverToRecipientsWithDeliverer(t *testing.T) {
+	var delivered []string
+	d := &queueDeliverer{}
+	a := &sideEffectActor{
+		s2s:       deliveringProtocol{delivered: &delivered},
+		deliverer: d,
+	}
+	outbox, err := url.Parse(testKeyOwner + "/outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recipients []*url.URL
+	for _, s := range []string{"https://example.net/users/bob/inbox", "https://example.org/users/carol/inbox"} {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recipients = append(recipients, u)
+	}
+	if err := a.deliverToRecipients(context.Background(), outbox, map[string]interface{}{"type": "Like"}, recipients); err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 0 {
+		t.Fatalf("expected no deliveries before the deliverer runs, got %v", delivered)
+	} else if len(d.queued) != len(recipients) {
+		t.Fatalf("expected %d scheduled deliveries, got %d", len(recipients), len(d.queued))
+	}
+	for _, fn := range d.queued {
+		if err := fn(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(delivered) != len(recipients) {
+		t.Fatalf("expected %d deliveries, got %v", len(recipients), delivered)
+	}
+}
+
+// blockingProtocol blocks every actor, recording those checked.
+type blockingProtocol struct {
+	FederatingProtocol
+	checked *[]string
+}
+
+func (b blockingProtocol) Blocked(c context.Context, actorIRIs []*url.URL) (bool, error) {
+	for _, iri := range actorIRIs {
+		*b.checked = append(*b.checked, iri.String())
+	}
+	return true, nil
+}
+
+func TestSideEffectActorDeliverLocallyWithDeliverer(t *testing.T) {
+	var checked []string
+	d := &queueDeliverer{}
+	a := &sideEffectActor{
+		s2s:       blockingProtocol{checked: &checked},
+		deliverer: d,
+	}
+	inbox, err := url.Parse("https://example.net/users/carol/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := map[string]interface{}{
+		"@context": "https://www.w3.org/ns/activitystreams",
+		"type":     "Like",
+		"actor":    testKeyOwner,
+		"object":   "https://example.net/notes/1",
+	}
+	if err := a.deliverLocally(context.Background(), m, []*url.URL{inbox}); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.queued) != 0 {
+		t.Fatalf("expected no scheduled deliveries, got %d", len(d.queued))
+	} else if len(checked) != 1 || checked[0] != testKeyOwner {
+		t.Fatalf("expected [%s], got %v", testKeyOwner, checked)
+	}
+}
+
+// outboxDatabase is a localActorsDatabase whose outboxes all belong to one
+// actor.
+type outboxDatabase struct {
+	localActorsDatabase
+	owner *url.URL
+}
+
+func (o outboxDatabase) ActorForOutbox(c context.Context, outboxIRI *url.URL) (*url.URL, error) {
+	return o.owner, nil
+}
+
+// dereferencingProtocol creates Transports dereferencing from a map.
+type dereferencingProtocol struct {
+	FederatingProtocol
+	docs     map[string][]byte
+	maxDepth int
+}
+
+func (d dereferencingProtocol) NewTransport(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error) {
+	return mapTransport{docs: d.docs}, nil
+}
+
+func (d dereferencingProtocol) MaxDeliveryRecursionDepth(c context.Context) int {
+	return d.maxDepth
+}
+
+func TestSideEffectActorPrepareSeparatesLocalRecipients(t *testing.T) {
+	const (
+		alice = "https://example.net/users/alice"
+		carol = "https://example.net/users/carol"
+		bob   = "https://example.org/users/bob"
+	)
+	owner, err := url.Parse(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &sideEffectActor{
+		db: outboxDatabase{
+			localActorsDatabase: newLocalActorsDatabase(t, nil, alice, carol),
+			owner:               owner,
+		},
+		// Only peers are dereferenced.
+		s2s: dereferencingProtocol{
+			docs: map[string][]byte{
+				bob: []byte(`{"@context":"https://www.w3.org/ns/activitystreams","id":"` + bob + `","type":"Person","inbox":"` + bob + `/inbox"}`),
+			},
+			maxDepth: 1,
+		},
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"@context":"https://www.w3.org/ns/activitystreams","id":"`+alice+`/likes/1","type":"Like","actor":"`+alice+`","attributedTo":"`+alice+`","to":["`+alice+`","`+carol+`","`+bob+`"],"object":"https://example.org/notes/1"}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	activity, err := toType(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, local, err := a.prepare(context.Background(), owner, activity.(Activity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remote) != 1 || remote[0].String() != bob+"/inbox" {
+		t.Fatalf("expected [%s/inbox], got %v", bob, remote)
+	} else if len(local) != 1 || local[0].String() != carol+"/inbox" {
+		t.Fatalf("expected [%s/inbox], got %v", carol, local)
+	}
+}
+
+func TestSideEffectActorPrepareOwnedCollection(t *testing.T) {
+	const (
+		alice     = "https://example.net/users/alice"
+		carol     = "https://example.net/users/carol"
+		dave      = "https://example.net/users/dave"
+		followers = alice + "/followers"
+	)
+	owner, err := url.Parse(alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newLocalActorsDatabase(t, nil, alice, carol, dave)
+	for id, doc := range map[string]string{
+		followers:             `{"@context":"https://www.w3.org/ns/activitystreams","id":"` + followers + `","type":"OrderedCollection","first":"` + followers + `?page=1"}`,
+		followers + "?page=1": `{"@context":"https://www.w3.org/ns/activitystreams","id":"` + followers + `?page=1","type":"OrderedCollectionPage","orderedItems":["` + carol + `"],"next":"` + followers + `?page=2"}`,
+		followers + "?page=2": `{"@context":"https://www.w3.org/ns/activitystreams","id":"` + followers + `?page=2","type":"OrderedCollectionPage","orderedItems":["` + dave + `"]}`,
+	} {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(doc), &m); err != nil {
+			t.Fatal(err)
+		}
+		if db.actors[id], err = toType(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := &sideEffectActor{
+		db: outboxDatabase{
+			localActorsDatabase: db,
+			owner:               owner,
+		},
+		// Nothing is dereferenced.
+		s2s: dereferencingProtocol{maxDepth: 2},
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"@context":"https://www.w3.org/ns/activitystreams","id":"`+alice+`/likes/1","type":"Like","actor":"`+alice+`","attributedTo":"`+alice+`","to":["`+followers+`"],"object":"https://example.org/notes/1"}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	activity, err := toType(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, local, err := a.prepare(context.Background(), owner, activity.(Activity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remote) != 0 {
+		t.Fatalf("expected no remote recipients, got %v", remote)
+	} else if len(local) != 2 || local[0].String() != carol+"/inbox" || local[1].String() != dave+"/inbox" {
+		t.Fatalf("expected [%s/inbox %s/inbox], got %v", carol, dave, local)
+	}
+}
+
+// appendingDatabase appends to inboxes and outboxes by IRI. It has no GetInbox
+// or GetOutbox, so the test panics if the inbox or outbox is rewritten.
+type appendingDatabase struct {
+	Database
+	inbox  []string
+	outbox []string
+}
+
+func (a *appendingDatabase) Lock(c context.Context, id *url.URL) error         { return nil }
+func (a *appendingDatabase) Unlock(c context.Context, id *url.URL) error       { return nil }
+func (a *appendingDatabase) Create(c context.Context, asType vocab.Type) error { return nil }
+
+func (a *appendingDatabase) AppendToInbox(c context.Context, inboxIRI, id *url.URL) (bool, error) {
+	for _, v := range a.inbox {
+		if v == id.String() {
+			return false, nil
+		}
+	}
+	a.inbox = append(a.inbox, id.String())
+	return true, nil
+}
+
+func (a *appendingDatabase) AppendToOutbox(c context.Context, outboxIRI, id *url.URL) error {
+	a.outbox = append(a.outbox, id.String())
+	return nil
+}
+
+func TestSideEffectActorAppendsToBoxes(t *testing.T) {
+	const id = "https://example.net/likes/1"
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"@context":"https://www.w3.org/ns/activitystreams","id":"`+id+`","type":"Like","actor":"https://example.net/users/alice","object":"https://example.org/notes/1"}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	activity, err := toType(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boxIRI, err := url.Parse("https://example.net/users/alice/box")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &appendingDatabase{}
+	a := &sideEffectActor{db: db}
+	for i, expectNew := range []bool{true, false} {
+		isNew, err := a.addToInboxIfNew(context.Background(), boxIRI, activity.(Activity))
+		if err != nil {
+			t.Fatal(err)
+		} else if isNew != expectNew {
+			t.Fatalf("(%d): expected %v, got %v", i, expectNew, isNew)
+		}
+	}
+	if err = a.addToOutbox(context.Background(), boxIRI, activity.(Activity)); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.inbox) != 1 || db.inbox[0] != id {
+		t.Fatalf("expected [%s], got %v", id, db.inbox)
+	} else if len(db.outbox) != 1 || db.outbox[0] != id {
+		t.Fatalf("expected [%s], got %v", id, db.outbox)
+	}
+}
+
+// socialCallbacksProtocol provides the other social callbacks.
+type socialCallbacksProtocol struct {
+	SocialProtocol
+	other []interface{}
+}
+
+func (p socialCallbacksProtocol) Callbacks(c context.Context) (SocialWrappedCallbacks, []interface{}) {
+	return SocialWrappedCallbacks{}, p.other
+}
+
+// newTestListen returns a Listen activity by the test key's owner.
+func newTestListen(t *testing.T) vocab.ActivityStreamsListen {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.com/listens/1","type":"Listen","actor":"`+testKeyOwner+`","object":"https://example.com/songs/1"}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	v, err := toType(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v.(vocab.ActivityStreamsListen)
+}
+
+func TestSideEffectActorPostInboxOtherCallbacks(t *testing.T) {
+	inboxIRI, err := url.Parse("https://example.net/users/bob/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listen := newTestListen(t)
+	var handled vocab.ActivityStreamsListen
+	a := &sideEffectActor{
+		db: &inboxDatabase{inbox: streams.NewActivityStreamsOrderedCollectionPage()},
+		s2s: callbacksProtocol{other: []interface{}{
+			func(c context.Context, l vocab.ActivityStreamsListen) error {
+				handled = l
+				return nil
+			},
+		}},
+	}
+	if err := a.PostInbox(context.Background(), inboxIRI, listen); err != nil {
+		t.Fatal(err)
+	} else if handled != listen {
+		t.Fatalf("expected %v, got %v", listen, handled)
+	}
+}
+
+func TestSideEffectActorPostOutboxReturnsCallbackError(t *testing.T) {
+	outboxIRI, err := url.Parse(testKeyOwner + "/outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fmt.Errorf("listen failed")
+	a := &sideEffectActor{
+		c2s: socialCallbacksProtocol{other: []interface{}{
+			func(c context.Context, l vocab.ActivityStreamsListen) error {
+				return expected
+			},
+		}},
+	}
+	if _, err := a.PostOutbox(context.Background(), newTestListen(t), outboxIRI, nil); err != expected {
+		t.Fatalf("expected %v, got %v", expected, err)
+	}
+}
@@ -30,6 +30,9 @@ type SocialProtocol interface {
 	// Finally, if the authentication and authorization succeeds, then
 	// shouldReturn must be false and error nil. The request will continue
 	// to be processed.
+	//
+	// A BearerAuthenticator may be used to authenticate requests with
+	// OAuth2 bearer tokens.
 	AuthenticatePostOutbox(c context.Context, w http.ResponseWriter, r *http.Request) (shouldReturn bool, err error)
 	// Callbacks returns the application logic that handles ActivityStreams
 	// received from C2S clients. Note that certain types of callbacks
@@ -70,6 +70,13 @@ type SocialWrappedCallbacks struct {
 	// Note that go-fed does not federate 'Block' activities received in the
 	// Social Protocol.
 	Block func(context.Context, vocab.ActivityStreamsBlock) error
+	// Default handles the side effects of activities that no other
+	// callback handles, including activities of types unknown to go-fed,
+	// which are received as an *UnknownActivity.
+	//
+	// The wrapping callback provides no default side effects, other than
+	// adding the activity to the outbox and delivering it.
+	Default func(context.Context, Activity) error
 
 	// Sidechannel data -- this is set at request handling time. These must
 	// be set before the callbacks are used.
@@ -485,3 +492,13 @@ func (w SocialWrappedCallbacks) block(c context.Context, a vocab.ActivityStreams
 	}
 	return nil
 }
+
+// defaultFn implements the social side effects of activities no other callback
+// handles.
+func (w SocialWrappedCallbacks) defaultFn(c context.Context, a Activity) error {
+	*w.deliverable = true
+	if w.Default != nil {
+		return w.Default(c, a)
+	}
+	return nil
+}
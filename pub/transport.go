@@ -7,16 +7,27 @@ import (
 	"fmt"
 	"github.com/go-fed/httpsig"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	// acceptHeaderValue is the Accept header value indicating that the
-	// response should contain an ActivityStreams object.
-	acceptHeaderValue = "application/ld+json; profile=\"https://www.w3.org/ns/activitystreams\""
+	// response should contain an ActivityStreams object, in either of its
+	// media types. The one the specification prefers is listed first.
+	acceptHeaderValue = "application/ld+json; profile=\"https://www.w3.org/ns/activitystreams\", application/activity+json; q=0.9"
+	// retryAfterHeader is the header a peer uses to ask for a delay before a
+	// request is tried again.
+	retryAfterHeader = "Retry-After"
+	// defaultBatchDeliveryWorkers is the number of deliveries BatchDeliver
+	// sends at once, unless configured otherwise.
+	defaultBatchDeliveryWorkers = 8
 )
 
 // Transport makes ActivityStreams calls to other servers in order to POST or
@@ -30,74 +41,265 @@ type Transport interface {
 	// Deliver sends an ActivityStreams object.
 	Deliver(c context.Context, b []byte, to *url.URL) error
 	// BatchDeliver sends an ActivityStreams object to multiple recipients.
+	//
+	// If delivery to any recipient fails, the error should be a
+	// *BatchDeliveryError so that the failed recipients can be retried.
 	BatchDeliver(c context.Context, b []byte, recipients []*url.URL) error
 }
 
+// Deliverer schedules federated ActivityPub messages for delivery, possibly
+// asynchronously and with retries.
+type Deliverer interface {
+	// Do schedules a message to be sent to a specific URL endpoint by
+	// using sendFn.
+	Do(b []byte, to *url.URL, sendFn func(b []byte, to *url.URL) error)
+}
+
+// scheduleDeliveries hands each recipient of a message to the Deliverer.
+//
+// The Deliverer may send after the request that triggered the delivery has
+// finished, and Transports may not be used concurrently, so every send creates
+// its own Transport without the request's context.
+func scheduleDeliveries(d Deliverer, newTransport func(c context.Context, actorBoxIRI *url.URL, gofedAgent string) (Transport, error), boxIRI *url.URL, b []byte, recipients []*url.URL) {
+	sendFn := func(b []byte, to *url.URL) error {
+		c := context.Background()
+		t, err := newTransport(c, boxIRI, goFedUserAgent())
+		if err != nil {
+			return err
+		}
+		return t.Deliver(c, b, to)
+	}
+	for _, to := range recipients {
+		d.Do(b, to, sendFn)
+	}
+}
+
 // Transport must be implemented by HttpSigTransport.
 var _ Transport = &HttpSigTransport{}
 
+// ConditionalDereferencer must be implemented by HttpSigTransport.
+var _ ConditionalDereferencer = &HttpSigTransport{}
+
 // HttpSigTransport makes a dereference call using HTTP signatures to
 // authenticate the request on behalf of a particular actor.
 //
 // No rate limiting is applied.
 //
 // Only one request is tried per call.
+//
+// Peers determine which IRIs are requested, so they are restricted by the
+// DefaultFetchPolicy unless the HttpClient was created by NewPolicyHttpClient,
+// which enforces its own FetchPolicy, or by WithoutFetchPolicy.
 type HttpSigTransport struct {
-	client     HttpClient
-	appAgent   string
-	gofedAgent string
-	clock      Clock
-	signer     httpsig.Signer
-	pubKeyId   string
-	privKey    crypto.PrivateKey
+	client       HttpClient
+	appAgent     string
+	gofedAgent   string
+	clock        Clock
+	getSigner    httpsig.Signer
+	postSigner   httpsig.Signer
+	keys         KeyProvider
+	batchWorkers int
+	observer     TransportObserver
+}
+
+// KeyProvider supplies the key that signs a request on behalf of an actor.
+//
+// The HttpSigTransport consults it on every request, so that once an actor's
+// key is rotated, all deliveries including retried ones are signed with the
+// new key.
+type KeyProvider interface {
+	// Key returns the id of the public key and the private key to sign
+	// with.
+	Key(c context.Context) (pubKeyId string, privKey crypto.PrivateKey, err error)
 }
 
-// NewHttpSigTransport returns a new HttpSigTransport.
+// staticKeyProvider always provides the same key.
+type staticKeyProvider struct {
+	pubKeyId string
+	privKey  crypto.PrivateKey
+}
+
+// Key returns the key.
+func (s staticKeyProvider) Key(c context.Context) (string, crypto.PrivateKey, error) {
+	return s.pubKeyId, s.privKey, nil
+}
+
+// NewHttpSigTransport returns a new HttpSigTransport that always signs with
+// the same key.
+//
+// Requests are signed with the first of the algorithms supported, or
+// RSA-SHA256 if none are provided. The signature covers the request target,
+// and the Host and Date headers. Deliveries also carry a SHA-256 Digest
+// header of their body, which the signature covers as well.
+//
+// The client is restricted by the DefaultFetchPolicy, unless it was created by
+// NewPolicyHttpClient or WithoutFetchPolicy. ErrUnrestrictedHttpClient is
+// returned for a client that is neither one of those nor an http.Client.
 func NewHttpSigTransport(
 	client HttpClient,
 	appAgent, gofedAgent string,
 	clock Clock,
-	signer httpsig.Signer,
+	algos []httpsig.Algorithm,
 	pubKeyId string,
-	privKey crypto.PrivateKey) *HttpSigTransport {
+	privKey crypto.PrivateKey) (*HttpSigTransport, error) {
+	return NewHttpSigTransportWithKeyProvider(client, appAgent, gofedAgent, clock, algos, staticKeyProvider{
+		pubKeyId: pubKeyId,
+		privKey:  privKey,
+	})
+}
+
+// NewHttpSigTransportWithKeyProvider returns a new HttpSigTransport that signs
+// each request with the key currently supplied by the KeyProvider.
+//
+// Requests are signed as by NewHttpSigTransport.
+func NewHttpSigTransportWithKeyProvider(
+	client HttpClient,
+	appAgent, gofedAgent string,
+	clock Clock,
+	algos []httpsig.Algorithm,
+	keys KeyProvider) (*HttpSigTransport, error) {
+	if len(algos) == 0 {
+		algos = []httpsig.Algorithm{httpsig.RSA_SHA256}
+	}
+	getSigner, _, err := httpsig.NewSigner(algos, requiredSignedHeaders, httpsig.Signature)
+	if err != nil {
+		return nil, err
+	}
+	postHeaders := append(requiredSignedHeaders[:len(requiredSignedHeaders):len(requiredSignedHeaders)], "digest")
+	postSigner, _, err := httpsig.NewSigner(algos, postHeaders, httpsig.Signature)
+	if err != nil {
+		return nil, err
+	}
+	client, err = restrictHttpClient(client)
+	if err != nil {
+		return nil, err
+	}
 	return &HttpSigTransport{
-		client:     client,
-		appAgent:   appAgent,
-		gofedAgent: gofedAgent,
-		clock:      clock,
-		signer:     signer,
-		pubKeyId:   pubKeyId,
-		privKey:    privKey,
+		client:       client,
+		appAgent:     appAgent,
+		gofedAgent:   gofedAgent,
+		clock:        clock,
+		getSigner:    getSigner,
+		postSigner:   postSigner,
+		keys:         keys,
+		batchWorkers: defaultBatchDeliveryWorkers,
+	}, nil
+}
+
+// SetBatchDeliveryWorkers sets how many deliveries BatchDeliver sends at once.
+// Values less than one are ignored.
+func (h *HttpSigTransport) SetBatchDeliveryWorkers(n int) {
+	if n > 0 {
+		h.batchWorkers = n
+	}
+}
+
+// TransportObserver is notified of every request an HttpSigTransport makes,
+// in order to monitor its peers.
+//
+// It is called from the goroutines making the requests, so it must be safe for
+// concurrent use and should return quickly.
+type TransportObserver interface {
+	// RequestFinished is called once a request has been responded to or
+	// has failed, with how long it took. The statusCode is zero if the
+	// peer did not respond.
+	RequestFinished(method string, iri *url.URL, statusCode int, latency time.Duration, err error)
+}
+
+// SetObserver sets the TransportObserver notified of every request. It is
+// optional.
+func (h *HttpSigTransport) SetObserver(o TransportObserver) {
+	h.observer = o
+}
+
+// observe notifies the observer, if there is one, of a finished request.
+func (h HttpSigTransport) observe(method string, iri *url.URL, statusCode int, start time.Time, err error) {
+	if h.observer != nil {
+		h.observer.RequestFinished(method, iri, statusCode, time.Since(start), err)
 	}
 }
 
+// sign signs the request with the current key. The signer reads the Host
+// header from the request's headers, although the client sends the request's
+// Host instead.
+func (h HttpSigTransport) sign(c context.Context, signer httpsig.Signer, req *http.Request) error {
+	pubKeyId, privKey, err := h.keys.Key(c)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(hostHeader, req.URL.Host)
+	return signer.SignRequest(privKey, pubKeyId, req)
+}
+
 // Dereferences with a request signed with an HTTP Signature.
 func (h HttpSigTransport) Dereference(c context.Context, iri *url.URL) ([]byte, error) {
+	resp, err := h.DereferenceIfModified(c, iri, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// DereferenceIfModified dereferences with a request signed with an HTTP
+// Signature, made conditional on the etag and lastModified values when they
+// are not empty.
+func (h HttpSigTransport) DereferenceIfModified(c context.Context, iri *url.URL, etag, lastModified string) (*DereferenceResponse, error) {
 	req, err := http.NewRequest("GET", iri.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	req.WithContext(c)
+	req = req.WithContext(c)
 	req.Header.Add(acceptHeader, acceptHeaderValue)
 	req.Header.Add("Accept-Charset", "utf-8")
 	req.Header.Add("Date", h.clock.Now().UTC().Format("Mon, 02 Jan 2006 15:04:05")+" GMT")
 	req.Header.Add("User-Agent", fmt.Sprintf("%s %s", h.appAgent, h.gofedAgent))
-	err = h.signer.SignRequest(h.privKey, h.pubKeyId, req)
+	if len(etag) > 0 {
+		req.Header.Add(ifNoneMatchHeader, etag)
+	}
+	if len(lastModified) > 0 {
+		req.Header.Add(ifModifiedSinceHeader, lastModified)
+	}
+	err = h.sign(c, h.getSigner, req)
 	if err != nil {
 		return nil, err
 	}
+	start := time.Now()
 	resp, err := h.client.Do(req)
 	if err != nil {
+		err = unwrapFetchError(err)
+		h.observe("GET", iri, 0, start, err)
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GET request to %s failed (%d): %s", iri.String(), resp.StatusCode, resp.Status)
+	dr, err := h.readDereference(iri, resp, len(etag) > 0 || len(lastModified) > 0)
+	h.observe("GET", iri, resp.StatusCode, start, err)
+	return dr, err
+}
+
+// readDereference reads the response to a dereference.
+func (h HttpSigTransport) readDereference(iri *url.URL, resp *http.Response, conditional bool) (*DereferenceResponse, error) {
+	if conditional && resp.StatusCode == http.StatusNotModified {
+		return &DereferenceResponse{
+			NotModified: true,
+			Header:      resp.Header,
+		}, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, newHttpStatusError("GET", iri, resp, h.clock.Now())
+	} else if ct := resp.Header.Get(contentTypeHeader); !isActivityStreamsResponse(ct) {
+		return nil, &UnexpectedContentTypeError{IRI: iri, ContentType: ct}
 	}
-	return ioutil.ReadAll(resp.Body)
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &DereferenceResponse{
+		Body:   b,
+		Header: resp.Header,
+	}, nil
 }
 
-// Deliver sends a POST request with an HTTP Signature.
+// Deliver sends a POST request with an HTTP Signature. Any 2xx status is a
+// successful delivery.
 func (h HttpSigTransport) Deliver(c context.Context, b []byte, to *url.URL) error {
 	byteCopy := make([]byte, len(b))
 	copy(byteCopy, b)
@@ -106,56 +308,208 @@ func (h HttpSigTransport) Deliver(c context.Context, b []byte, to *url.URL) erro
 	if err != nil {
 		return err
 	}
-	req.WithContext(c)
+	req = req.WithContext(c)
 	req.Header.Add(contentTypeHeader, contentTypeHeaderValue)
 	req.Header.Add("Accept-Charset", "utf-8")
 	req.Header.Add("Date", h.clock.Now().UTC().Format("Mon, 02 Jan 2006 15:04:05")+" GMT")
 	req.Header.Add("User-Agent", fmt.Sprintf("%s %s", h.appAgent, h.gofedAgent))
-	err = h.signer.SignRequest(h.privKey, h.pubKeyId, req)
+	req.Header.Add(digestHeader, digest(b))
+	err = h.sign(c, h.postSigner, req)
 	if err != nil {
 		return err
 	}
+	start := time.Now()
 	resp, err := h.client.Do(req)
 	if err != nil {
+		err = unwrapFetchError(err)
+		h.observe("POST", to, 0, start, err)
 		return err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("POST request to %s failed (%d): %s", to.String(), resp.StatusCode, resp.Status)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = newHttpStatusError("POST", to, resp, h.clock.Now())
 	}
-	return nil
+	h.observe("POST", to, resp.StatusCode, start, err)
+	return err
 }
 
-// BatchDeliver sends concurrent POST requests. Returns an error if any of the
+// BatchDeliver sends concurrent POST requests, no more at once than its
+// configured number of workers. Returns a *BatchDeliveryError if any of the
 // requests had an error.
+//
+// Once the context is done, recipients not yet delivered to fail with the
+// context's error.
 func (h HttpSigTransport) BatchDeliver(c context.Context, b []byte, recipients []*url.URL) error {
+	workers := h.batchWorkers
+	if workers <= 0 {
+		workers = defaultBatchDeliveryWorkers
+	}
+	var mu sync.Mutex
+	errs := make(map[string]error)
+	next := make(chan *url.URL)
 	var wg sync.WaitGroup
-	errCh := make(chan error, len(recipients))
-	for _, recipient := range recipients {
+	for i := 0; i < workers && i < len(recipients); i++ {
 		wg.Add(1)
-		go func(r *url.URL) {
+		go func() {
 			defer wg.Done()
-			if err := h.Deliver(c, b, r); err != nil {
-				errCh <- err
+			for r := range next {
+				var err error
+				if err = c.Err(); err == nil {
+					err = h.Deliver(c, b, r)
+				}
+				if err != nil {
+					mu.Lock()
+					errs[r.String()] = err
+					mu.Unlock()
+				}
 			}
-		}(recipient)
+		}()
 	}
-	wg.Wait()
-	errs := make([]string, 0, len(recipients))
-	for {
-		select {
-		case e := <-errCh:
-			errs = append(errs, e.Error())
-		default:
-			break
-		}
+	for _, r := range recipients {
+		next <- r
 	}
+	close(next)
+	wg.Wait()
 	if len(errs) > 0 {
-		return fmt.Errorf("batch deliver had at least one failure: %s", strings.Join(errs, "; "))
+		return &BatchDeliveryError{Errors: errs}
 	}
 	return nil
 }
 
+// BatchDeliveryError is returned by BatchDeliver when delivery to at least one
+// recipient failed.
+type BatchDeliveryError struct {
+	// Errors maps the IRI of each failed recipient to its error.
+	Errors map[string]error
+}
+
+// Error lists each failed recipient and its error.
+func (e *BatchDeliveryError) Error() string {
+	errs := make([]string, 0, len(e.Errors))
+	for iri, err := range e.Errors {
+		errs = append(errs, fmt.Sprintf("%s=%s", iri, err))
+	}
+	sort.Strings(errs)
+	return fmt.Sprintf("batch deliver had at least one failure: %s", strings.Join(errs, "; "))
+}
+
+// Recipients returns the IRIs of the failed recipients, in order to retry
+// them.
+func (e *BatchDeliveryError) Recipients() ([]*url.URL, error) {
+	iris := make([]string, 0, len(e.Errors))
+	for iri := range e.Errors {
+		iris = append(iris, iri)
+	}
+	sort.Strings(iris)
+	recipients := make([]*url.URL, 0, len(iris))
+	for _, iri := range iris {
+		u, err := url.Parse(iri)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, u)
+	}
+	return recipients, nil
+}
+
+// UnexpectedContentTypeError is returned by an HttpSigTransport when a peer
+// responds to a dereference with something other than ActivityStreams data,
+// such as an HTML page.
+type UnexpectedContentTypeError struct {
+	// IRI is the IRI that was dereferenced.
+	IRI *url.URL
+	// ContentType is the Content-Type header of the response.
+	ContentType string
+}
+
+// Error describes the request and the unexpected Content-Type.
+func (e *UnexpectedContentTypeError) Error() string {
+	return fmt.Sprintf("GET request to %s responded with %s %q, not ActivityStreams", e.IRI.String(), contentTypeHeader, e.ContentType)
+}
+
+// isActivityStreamsResponse returns true if the Content-Type of a response is
+// one of the ActivityStreams media types.
+//
+// Since many servers omit the profile, plain application/ld+json is also
+// accepted, unlike in the requests the library serves.
+func isActivityStreamsResponse(contentType string) bool {
+	ranges := parseMediaRanges(contentType)
+	if len(ranges) != 1 {
+		return false
+	}
+	return ranges[0].isActivityStreams() || ranges[0].mediaType == jsonLDMediaType
+}
+
+// HttpStatusError is returned by an HttpSigTransport when a peer responds with
+// an unsuccessful status.
+type HttpStatusError struct {
+	// Method is the method of the request.
+	Method string
+	// IRI is the IRI the request was sent to.
+	IRI *url.URL
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Status is the status line of the response.
+	Status string
+	// RetryAfter is how long the peer asked to wait before trying again
+	// with its Retry-After header, or zero if it did not.
+	RetryAfter time.Duration
+}
+
+// newHttpStatusError creates an HttpStatusError from a response.
+func newHttpStatusError(method string, iri *url.URL, resp *http.Response, now time.Time) *HttpStatusError {
+	return &HttpStatusError{
+		Method:     method,
+		IRI:        iri,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RetryAfter: parseRetryAfter(resp.Header.Get(retryAfterHeader), now),
+	}
+}
+
+// Error describes the request and the status of its response.
+func (e *HttpStatusError) Error() string {
+	return fmt.Sprintf("%s request to %s failed (%d): %s", e.Method, e.IRI.String(), e.StatusCode, e.Status)
+}
+
+// Gone is true if the peer indicated that the IRI no longer exists and never
+// will again.
+func (e *HttpStatusError) Gone() bool {
+	return e.StatusCode == http.StatusGone
+}
+
+// Permanent is true if repeating the same request cannot succeed. This is the
+// case for client errors, other than timeouts and rate limiting.
+func (e *HttpStatusError) Permanent() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 &&
+		e.StatusCode != http.StatusRequestTimeout &&
+		e.StatusCode != http.StatusTooManyRequests
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date, into a delay from now.
+//
+// Missing, malformed, and past values are zero. Values too large to be
+// represented are the longest possible delay; callers are expected to bound it.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if len(v) == 0 {
+		return 0
+	}
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs <= 0 {
+			return 0
+		} else if secs > int64(math.MaxInt64/time.Second) {
+			return time.Duration(math.MaxInt64)
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
 // HttpClient sends http requests, and is an abstraction only needed by the
 // HttpSigTransport. The standard library's Client satisfies this interface.
 type HttpClient interface {
@@ -0,0 +1,378 @@
+package pub
+
+import (
+	"context"
+	"crypto"
+	"github.com/go-fed/httpsig"
+	"github.com/go-test/deep"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingClient records the requests it is sent and responds with a status
+// and headers.
+type recordingClient struct {
+	status int
+	header http.Header
+	reqs   []*http.Request
+}
+
+func (r *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	r.reqs = append(r.reqs, req)
+	return &http.Response{
+		StatusCode: r.status,
+		Status:     http.StatusText(r.status),
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Header:     r.header,
+	}, nil
+}
+
+// statusObserver records the status of every request it is notified of.
+type statusObserver struct {
+	statuses []int
+}
+
+func (s *statusObserver) RequestFinished(method string, iri *url.URL, statusCode int, latency time.Duration, err error) {
+	s.statuses = append(s.statuses, statusCode)
+}
+
+// rotatingKeyProvider provides whichever key is current.
+type rotatingKeyProvider struct {
+	pubKeyId *string
+	privKey  crypto.PrivateKey
+}
+
+func (r rotatingKeyProvider) Key(c context.Context) (string, crypto.PrivateKey, error) {
+	return *r.pubKeyId, r.privKey, nil
+}
+
+func TestHttpSigTransportKeyProvider(t *testing.T) {
+	privKey, _ := newTestKeyPair(t)
+	keyId := testKeyId
+	client := &recordingClient{status: http.StatusOK, header: http.Header{}}
+	tp, err := NewHttpSigTransportWithKeyProvider(WithoutFetchPolicy(client), "app", "go-fed", fixedClock(time.Now()), nil, rotatingKeyProvider{pubKeyId: &keyId, privKey: privKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := url.Parse("https://example.net/users/bob/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		keyId string
+	}{
+		{
+			name:  "original key",
+			keyId: testKeyId,
+		},
+		{
+			name:  "rotated key",
+			keyId: "https://example.com/users/alice#key-2",
+		},
+	}
+	for i, test := range tests {
+		keyId = test.keyId
+		if err := tp.Deliver(context.Background(), []byte("{}"), to); err != nil {
+			t.Fatal(err)
+		}
+		v, err := httpsig.NewVerifier(client.reqs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := v.KeyId(); actual != test.keyId {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.keyId, actual)
+		}
+		for _, h := range []string{httpsig.RequestTarget, "host", "date", "digest"} {
+			if !isSignedHeader(client.reqs[i].Header, h) {
+				t.Fatalf("(%q): expected %q to be signed, got %v", test.name, h, signedHeaders(client.reqs[i].Header))
+			}
+		}
+	}
+}
+
+func TestHttpSigTransportDeliverStatus(t *testing.T) {
+	now := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	privKey, _ := newTestKeyPair(t)
+	to, err := url.Parse("https://example.net/users/bob/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		expectErr  bool
+		gone       bool
+		permanent  bool
+		expectWait time.Duration
+	}{
+		{
+			name:   "ok",
+			status: http.StatusOK,
+		},
+		{
+			name:   "accepted",
+			status: http.StatusAccepted,
+		},
+		{
+			name:   "no content",
+			status: http.StatusNoContent,
+		},
+		{
+			name:      "gone",
+			status:    http.StatusGone,
+			expectErr: true,
+			gone:      true,
+			permanent: true,
+		},
+		{
+			name:      "forbidden",
+			status:    http.StatusForbidden,
+			expectErr: true,
+			permanent: true,
+		},
+		{
+			name:       "rate limited in seconds",
+			status:     http.StatusTooManyRequests,
+			retryAfter: "120",
+			expectErr:  true,
+			expectWait: 2 * time.Minute,
+		},
+		{
+			name:       "rate limited for longer than representable",
+			status:     http.StatusTooManyRequests,
+			retryAfter: "9223372036854775807",
+			expectErr:  true,
+			expectWait: time.Duration(math.MaxInt64),
+		},
+		{
+			name:       "unavailable until a date",
+			status:     http.StatusServiceUnavailable,
+			retryAfter: "Wed, 02 Jan 2019 03:05:05 GMT",
+			expectErr:  true,
+			expectWait: time.Minute,
+		},
+		{
+			name:       "malformed retry after",
+			status:     http.StatusServiceUnavailable,
+			retryAfter: "soon",
+			expectErr:  true,
+		},
+	}
+	for _, test := range tests {
+		client := &recordingClient{status: test.status, header: http.Header{}}
+		if len(test.retryAfter) > 0 {
+			client.header.Set(retryAfterHeader, test.retryAfter)
+		}
+		tp, err := NewHttpSigTransport(WithoutFetchPolicy(client), "app", "go-fed", fixedClock(now), nil, testKeyId, privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := &statusObserver{}
+		tp.SetObserver(o)
+		err = tp.Deliver(context.Background(), []byte("{}"), to)
+		if len(o.statuses) != 1 || o.statuses[0] != test.status {
+			t.Fatalf("(%q): expected observed status %v, got %v", test.name, test.status, o.statuses)
+		}
+		if !test.expectErr {
+			if err != nil {
+				t.Fatalf("(%q): unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		se, ok := err.(*HttpStatusError)
+		if !ok {
+			t.Fatalf("(%q): expected *HttpStatusError, got %v", test.name, err)
+		} else if se.StatusCode != test.status {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.status, se.StatusCode)
+		} else if se.Gone() != test.gone {
+			t.Fatalf("(%q): expected gone %v, got %v", test.name, test.gone, se.Gone())
+		} else if se.Permanent() != test.permanent {
+			t.Fatalf("(%q): expected permanent %v, got %v", test.name, test.permanent, se.Permanent())
+		} else if se.RetryAfter != test.expectWait {
+			t.Fatalf("(%q): expected %v, got %v", test.name, test.expectWait, se.RetryAfter)
+		}
+	}
+}
+
+// hostStatusClient responds with a status depending on the request's host,
+// tracking the most requests it has seen in flight at once.
+type hostStatusClient struct {
+	mu          sync.Mutex
+	status      map[string]int
+	inFlight    int
+	maxInFlight int
+}
+
+func (h *hostStatusClient) Do(req *http.Request) (*http.Response, error) {
+	h.mu.Lock()
+	h.inFlight++
+	if h.inFlight > h.maxInFlight {
+		h.maxInFlight = h.inFlight
+	}
+	status, ok := h.status[req.URL.Host]
+	h.mu.Unlock()
+	time.Sleep(time.Millisecond)
+	h.mu.Lock()
+	h.inFlight--
+	h.mu.Unlock()
+	if !ok {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Header:     http.Header{},
+	}, nil
+}
+
+func TestHttpSigTransportBatchDeliver(t *testing.T) {
+	privKey, _ := newTestKeyPair(t)
+	var recipients []*url.URL
+	for _, host := range []string{"a.example", "b.example", "c.example", "d.example", "e.example"} {
+		u, err := url.Parse("https://" + host + "/inbox")
+		if err != nil {
+			t.Fatal(err)
+		}
+		recipients = append(recipients, u)
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name     string
+		c        context.Context
+		status   map[string]int
+		expected []string
+	}{
+		{
+			name: "all delivered",
+			c:    context.Background(),
+		},
+		{
+			name:     "some failed",
+			c:        context.Background(),
+			status:   map[string]int{"b.example": http.StatusInternalServerError, "d.example": http.StatusGone},
+			expected: []string{"https://b.example/inbox", "https://d.example/inbox"},
+		},
+		{
+			name:     "cancelled",
+			c:        cancelled,
+			expected: []string{"https://a.example/inbox", "https://b.example/inbox", "https://c.example/inbox", "https://d.example/inbox", "https://e.example/inbox"},
+		},
+	}
+	for _, test := range tests {
+		client := &hostStatusClient{status: test.status}
+		tp, err := NewHttpSigTransport(WithoutFetchPolicy(client), "app", "go-fed", fixedClock(time.Now()), nil, testKeyId, privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tp.SetBatchDeliveryWorkers(2)
+		err = tp.BatchDeliver(test.c, []byte("{}"), recipients)
+		if client.maxInFlight > 2 {
+			t.Fatalf("(%q): expected at most 2 requests in flight, got %d", test.name, client.maxInFlight)
+		}
+		if len(test.expected) == 0 {
+			if err != nil {
+				t.Fatalf("(%q): unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		bErr, ok := err.(*BatchDeliveryError)
+		if !ok {
+			t.Fatalf("(%q): expected *BatchDeliveryError, got %v", test.name, err)
+		}
+		failed, err := bErr.Recipients()
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := make([]string, 0, len(failed))
+		for _, u := range failed {
+			actual = append(actual, u.String())
+		}
+		if diff := deep.Equal(actual, test.expected); diff != nil {
+			t.Fatalf("(%q): %v", test.name, diff)
+		}
+	}
+}
+
+// contentTypeClient responds to every request with a body of a Content-Type.
+// It is also an http.RoundTripper, so that an http.Client may use it.
+type contentTypeClient struct {
+	contentType string
+	req         *http.Request
+}
+
+func (c *contentTypeClient) RoundTrip(req *http.Request) (*http.Response, error) {
+	return c.Do(req)
+}
+
+func (c *contentTypeClient) Do(req *http.Request) (*http.Response, error) {
+	c.req = req
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     http.StatusText(http.StatusOK),
+		Body:       ioutil.NopCloser(strings.NewReader(`{"type":"Person"}`)),
+		Header:     http.Header{contentTypeHeader: {c.contentType}},
+	}, nil
+}
+
+func TestHttpSigTransportDereferenceContentType(t *testing.T) {
+	privKey, _ := newTestKeyPair(t)
+	iri, err := url.Parse(testKeyOwner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		expectErr   bool
+	}{
+		{
+			name:        "activity json",
+			contentType: "application/activity+json; charset=utf-8",
+		},
+		{
+			name:        "json-ld with profile",
+			contentType: "application/ld+json; profile=\"https://www.w3.org/ns/activitystreams\"",
+		},
+		{
+			name:        "json-ld without profile",
+			contentType: "application/ld+json",
+		},
+		{
+			name:        "html",
+			contentType: "text/html; charset=utf-8",
+			expectErr:   true,
+		},
+		{
+			name:      "missing",
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		client := &contentTypeClient{contentType: test.contentType}
+		tp, err := NewHttpSigTransport(WithoutFetchPolicy(client), "app", "go-fed", fixedClock(time.Now()), nil, testKeyId, privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tp.Dereference(context.Background(), iri)
+		if test.expectErr {
+			if _, ok := err.(*UnexpectedContentTypeError); !ok {
+				t.Fatalf("(%q): expected *UnexpectedContentTypeError, got %v", test.name, err)
+			}
+		} else if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		if accept := client.req.Header.Get(acceptHeader); !headerIsActivityPubMediaType(accept) || !strings.Contains(accept, activityJSONMediaType) {
+			t.Fatalf("(%q): expected both media types in %s, got %q", test.name, acceptHeader, accept)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package pub
+
+import (
+	"context"
+	"github.com/go-fed/activity/streams"
+	"github.com/go-fed/activity/streams/vocab"
+)
+
+var _ Activity = &UnknownActivity{}
+
+// UnknownActivity is an Activity of a type unknown to go-fed, such as an
+// extension type like EmojiReact or ChatMessage.
+//
+// It provides the properties common to all Activities, such as the id, actor,
+// object, and addressing properties, and keeps all other properties it was
+// received with so that it is stored and forwarded unchanged. Applications
+// handle it in the Default callback of the FederatingWrappedCallbacks and
+// SocialWrappedCallbacks.
+type UnknownActivity struct {
+	vocab.ActivityStreamsActivity
+	// name is the first of the activity's types.
+	name string
+	// typeValue is the 'type' property as received.
+	typeValue interface{}
+	// raw is the activity as received.
+	raw map[string]interface{}
+}
+
+// toUnknownActivity converts a generic map[string]interface{} with a type not
+// known to go-fed into an UnknownActivity.
+//
+// Only a value with a 'type' and an 'actor' is considered an activity. Others
+// are rejected with streams.ErrUnhandledType.
+func toUnknownActivity(c context.Context, m map[string]interface{}) (*UnknownActivity, error) {
+	name := firstTypeName(m["type"])
+	if len(name) == 0 || m["actor"] == nil {
+		return nil, streams.ErrUnhandledType
+	}
+	// Deserialize the properties common to all Activities as if it were
+	// one, keeping the rest as unknown properties.
+	generic := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		generic[k] = v
+	}
+	generic["type"] = "Activity"
+	var activity vocab.ActivityStreamsActivity
+	r, err := streams.NewJSONResolver(func(ctx context.Context, a vocab.ActivityStreamsActivity) error {
+		activity = a
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = r.Resolve(c, generic); err != nil {
+		return nil, err
+	}
+	return &UnknownActivity{
+		ActivityStreamsActivity: activity,
+		name:                    name,
+		typeValue:               m["type"],
+		raw:                     m,
+	}, nil
+}
+
+// firstTypeName returns the first type of a 'type' property, or an empty string
+// if there is none.
+func firstTypeName(t interface{}) string {
+	switch v := t.(type) {
+	case string:
+		return v
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// GetName returns the activity's type, or the first of its types.
+func (u *UnknownActivity) GetName() string {
+	return u.name
+}
+
+// Serialize converts the activity into an interface representation suitable
+// for marshalling, with its original type and any changes made to its
+// properties since it was received.
+func (u *UnknownActivity) Serialize() (map[string]interface{}, error) {
+	m, err := u.ActivityStreamsActivity.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	m["type"] = u.typeValue
+	return m, nil
+}
+
+// Raw returns the activity as it was received, including its '@context'.
+//
+// It must not be modified.
+func (u *UnknownActivity) Raw() map[string]interface{} {
+	return u.raw
+}
@@ -0,0 +1,146 @@
+package pub
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-fed/activity/streams"
+	"github.com/go-fed/activity/streams/vocab"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const emojiReact = `{
+  "@context": ["https://www.w3.org/ns/activitystreams", {"litepub": "http://litepub.social/ns#", "EmojiReact": "litepub:EmojiReact"}],
+  "id": "https://example.org/reactions/1",
+  "type": "EmojiReact",
+  "actor": "https://example.org/users/bob",
+  "object": "https://example.net/notes/1",
+  "to": "https://example.net/users/alice",
+  "content": "🦆"
+}`
+
+func TestToTypeUnknownActivity(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		expectErr error
+	}{
+		{
+			name: "activity",
+			json: emojiReact,
+		},
+		{
+			name:      "no actor",
+			json:      `{"@context":"https://www.w3.org/ns/activitystreams","id":"https://example.org/recipes/1","type":"Recipe"}`,
+			expectErr: streams.ErrUnhandledType,
+		},
+	}
+	for _, test := range tests {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(test.json), &m); err != nil {
+			t.Fatal(err)
+		}
+		v, err := toType(context.Background(), m)
+		if test.expectErr != nil {
+			if err != test.expectErr {
+				t.Fatalf("(%q): expected %v, got %v", test.name, test.expectErr, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		u, ok := v.(*UnknownActivity)
+		if !ok {
+			t.Fatalf("(%q): expected *UnknownActivity, got %T", test.name, v)
+		} else if u.GetName() != "EmojiReact" {
+			t.Fatalf("(%q): expected %v, got %v", test.name, "EmojiReact", u.GetName())
+		} else if !IsAnActivityType(u) {
+			t.Fatalf("(%q): expected an activity type", test.name)
+		} else if id := u.GetActivityStreamsId(); id == nil || id.Get().String() != m["id"] {
+			t.Fatalf("(%q): expected %v, got %v", test.name, m["id"], id)
+		} else if actor := u.GetActivityStreamsActor(); actor == nil || actor.Len() != 1 || actor.At(0).GetIRI().String() != m["actor"] {
+			t.Fatalf("(%q): expected actor %v", test.name, m["actor"])
+		} else if to := u.GetActivityStreamsTo(); to == nil || to.Len() != 1 {
+			t.Fatalf("(%q): expected addressing %v", test.name, m["to"])
+		}
+		s, err := serialize(u)
+		if err != nil {
+			t.Fatalf("(%q): unexpected error: %v", test.name, err)
+		}
+		for _, k := range []string{"@context", "type", "content"} {
+			if !reflect.DeepEqual(s[k], m[k]) {
+				t.Fatalf("(%q): expected %v, got %v", test.name, m[k], s[k])
+			}
+		}
+	}
+}
+
+// inboxDatabase holds a single, initially empty inbox.
+type inboxDatabase struct {
+	Database
+	inbox vocab.ActivityStreamsOrderedCollectionPage
+}
+
+func (i *inboxDatabase) Lock(c context.Context, id *url.URL) error   { return nil }
+func (i *inboxDatabase) Unlock(c context.Context, id *url.URL) error { return nil }
+
+func (i *inboxDatabase) InboxContains(c context.Context, inbox, id *url.URL) (bool, error) {
+	return false, nil
+}
+
+func (i *inboxDatabase) GetInbox(c context.Context, inboxIRI *url.URL) (vocab.ActivityStreamsOrderedCollectionPage, error) {
+	return i.inbox, nil
+}
+
+func (i *inboxDatabase) SetInbox(c context.Context, inbox vocab.ActivityStreamsOrderedCollectionPage) error {
+	i.inbox = inbox
+	return nil
+}
+
+// callbacksProtocol provides the wrapped and other callbacks.
+type callbacksProtocol struct {
+	FederatingProtocol
+	wrapped FederatingWrappedCallbacks
+	other   []interface{}
+}
+
+func (p callbacksProtocol) Callbacks(c context.Context) (FederatingWrappedCallbacks, []interface{}) {
+	return p.wrapped, p.other
+}
+
+func TestSideEffectActorPostInboxUnknownActivity(t *testing.T) {
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(emojiReact), &m); err != nil {
+		t.Fatal(err)
+	}
+	v, err := toType(context.Background(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inboxIRI, err := url.Parse("https://example.net/users/alice/inbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var handled Activity
+	db := &inboxDatabase{inbox: streams.NewActivityStreamsOrderedCollectionPage()}
+	a := &sideEffectActor{
+		db: db,
+		s2s: callbacksProtocol{wrapped: FederatingWrappedCallbacks{
+			Default: func(c context.Context, a Activity) error {
+				handled = a
+				return nil
+			},
+		}},
+	}
+	if err = a.PostInbox(context.Background(), inboxIRI, v.(Activity)); err != nil {
+		t.Fatal(err)
+	}
+	if handled != v {
+		t.Fatalf("expected %v, got %v", v, handled)
+	}
+	oi := db.inbox.GetActivityStreamsOrderedItems()
+	if oi == nil || oi.Len() != 1 || oi.At(0).GetIRI().String() != m["id"] {
+		t.Fatalf("expected %v in the inbox", m["id"])
+	}
+}
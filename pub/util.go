@@ -11,6 +11,7 @@ import (
 	"github.com/go-fed/activity/streams/vocab"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -24,38 +25,117 @@ var (
 	// set. Can be returned by DelegateActor's PostInbox or PostOutbox so a
 	// Bad Request response is set.
 	ErrTargetRequired = errors.New("target property required on the provided activity")
+	// ErrDigestMissing indicates a request has no SHA-256 Digest header
+	// when one is required.
+	ErrDigestMissing = errors.New("request has no SHA-256 digest")
+	// ErrDigestMismatch indicates a request's SHA-256 Digest header does
+	// not match its body.
+	ErrDigestMismatch = errors.New("request digest does not match its body")
+	// ErrDigestUnsigned indicates a request's Digest header is not covered
+	// by its HTTP Signature, so it may not be the one that was signed.
+	ErrDigestUnsigned = errors.New("request digest is not covered by its http signature")
+	// ErrInboxQueueFull indicates an InboxQueue has no room for another
+	// activity, or has been stopped.
+	ErrInboxQueueFull = errors.New("inbox queue is full")
+	// ErrInboxQueueInUse indicates an Actor was created with an InboxQueue
+	// that is already used by another Actor.
+	ErrInboxQueueInUse = errors.New("inbox queue is already used by another actor")
+	// ErrInvalidCursor indicates the cursor of a requested inbox or outbox
+	// page is not valid. Can be returned by an InboxPager or OutboxPager
+	// so a Bad Request response is set.
+	ErrInvalidCursor = errors.New("invalid collection page cursor")
+	// ErrNoRequester indicates an activity embedding objects was received
+	// without the actor that sent it having been verified with
+	// SetRequester, so their origin cannot be checked.
+	ErrNoRequester = errors.New("no verified requester for the activity")
+	// ErrUnrestrictedHttpClient indicates an HttpClient given to an
+	// HttpSigTransport is neither an http.Client nor opted out of the
+	// DefaultFetchPolicy with WithoutFetchPolicy, so the policy cannot be
+	// enforced on the redirects it follows.
+	ErrUnrestrictedHttpClient = errors.New("http client cannot be restricted by the fetch policy")
 )
 
-// activityStreamsMediaTypes contains all of the accepted ActivityStreams media
-// types. Generated at init time.
-var activityStreamsMediaTypes []string
+const (
+	// The media type identifying ActivityStreams data on its own.
+	activityJSONMediaType = "application/activity+json"
+	// The JSON-LD media type, which identifies ActivityStreams data when
+	// it has the ActivityStreams profile.
+	jsonLDMediaType = "application/ld+json"
+	// The profile of JSON-LD that is ActivityStreams data.
+	activityStreamsProfile = "https://www.w3.org/ns/activitystreams"
+)
+
+// mediaRange is one of the comma separated media types in an Accept or
+// Content-Type header, with its parameters.
+type mediaRange struct {
+	mediaType string
+	params    map[string]string
+}
 
-func init() {
-	activityStreamsMediaTypes = []string{
-		"application/activity+json",
+// parseMediaRanges parses the media types in a header.
+//
+// Note we don't try to build a comprehensive parser and instead accept a
+// tolerable amount of whitespace since the HTTP specification is ambiguous
+// about the format and significance of whitespace. Unlike mime.ParseMediaType,
+// unquoted parameter values such as a profile URI are accepted.
+func parseMediaRanges(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, r := range strings.Split(header, ",") {
+		parts := strings.Split(r, ";")
+		mr := mediaRange{
+			mediaType: strings.ToLower(strings.TrimSpace(parts[0])),
+			params:    make(map[string]string, len(parts)-1),
+		}
+		if len(mr.mediaType) == 0 {
+			continue
+		}
+		for _, param := range parts[1:] {
+			kv := strings.SplitN(param, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			k := strings.ToLower(strings.TrimSpace(kv[0]))
+			mr.params[k] = strings.Trim(strings.TrimSpace(kv[1]), "\"")
+		}
+		ranges = append(ranges, mr)
 	}
-	jsonLdType := "application/ld+json"
-	for _, semi := range []string{";", " ;", " ; ", "; "} {
-		for _, profile := range []string{
-			"profile=https://www.w3.org/ns/activitystreams",
-			"profile=\"https://www.w3.org/ns/activitystreams\"",
-		} {
-			activityStreamsMediaTypes = append(
-				activityStreamsMediaTypes,
-				fmt.Sprintf("%s%s%s", jsonLdType, semi, profile))
+	return ranges
+}
+
+// isActivityStreams returns true if the media type is application/activity+json,
+// or application/ld+json with the ActivityStreams profile.
+//
+// The profile parameter may list several profiles, separated by spaces.
+func (m mediaRange) isActivityStreams() bool {
+	switch m.mediaType {
+	case activityJSONMediaType:
+		return true
+	case jsonLDMediaType:
+		for _, profile := range strings.Fields(m.params["profile"]) {
+			if profile == activityStreamsProfile {
+				return true
+			}
 		}
 	}
+	return false
+}
+
+// isAcceptable returns false if the media range has a quality of zero, which
+// marks it as not acceptable.
+func (m mediaRange) isAcceptable() bool {
+	q, ok := m.params["q"]
+	if !ok {
+		return true
+	}
+	v, err := strconv.ParseFloat(q, 64)
+	return err != nil || v > 0
 }
 
 // headerIsActivityPubMediaType returns true if the header string contains one
 // of the accepted ActivityStreams media types.
-//
-// Note we don't try to build a comprehensive parser and instead accept a
-// tolerable amount of whitespace since the HTTP specification is ambiguous
-// about the format and significance of whitespace.
 func headerIsActivityPubMediaType(header string) bool {
-	for _, mediaType := range activityStreamsMediaTypes {
-		if strings.Contains(header, mediaType) {
+	for _, m := range parseMediaRanges(header) {
+		if m.isActivityStreams() && m.isAcceptable() {
 			return true
 		}
 	}
@@ -156,6 +236,13 @@ func serialize(a vocab.Type) (m map[string]interface{}, e error) {
 		}
 		contextValue = append(arr, aliases)
 	}
+	// Keep the context an unknown activity was received with, as it may
+	// define the activity's type and properties.
+	if u, ok := a.(*UnknownActivity); ok {
+		if raw, ok := u.Raw()[jsonLDContext]; ok {
+			contextValue = raw
+		}
+	}
 	m[jsonLDContext] = contextValue
 	return
 }
@@ -180,12 +267,40 @@ func addResponseHeaders(h http.Header, c Clock, responseContent []byte) {
 	// RFC 7231 §7.1.1.2
 	h.Set(dateHeader, c.Now().UTC().Format("Mon, 02 Jan 2006 15:04:05")+" GMT")
 	// RFC 3230 and RFC 5843
+	h.Set(digestHeader, digest(responseContent))
+}
+
+// digest computes the value of the Digest header for the given content.
+func digest(content []byte) string {
 	var b bytes.Buffer
 	b.WriteString(sha256Digest)
 	b.WriteString(digestDelimiter)
-	hashed := sha256.Sum256(responseContent)
+	hashed := sha256.Sum256(content)
 	b.WriteString(base64.StdEncoding.EncodeToString(hashed[:]))
-	h.Set(digestHeader, b.String())
+	return b.String()
+}
+
+// verifyDigest ensures the Digest header contains a SHA-256 digest that
+// matches the content, and is covered by the HTTP Signature. Other digest
+// algorithms in the header are ignored.
+func verifyDigest(h http.Header, content []byte) error {
+	header := h.Get(digestHeader)
+	if len(header) == 0 {
+		return ErrDigestMissing
+	} else if !isSignedHeader(h, digestHeader) {
+		return ErrDigestUnsigned
+	}
+	for _, d := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(d), digestDelimiter, 2)
+		if len(kv) != 2 || !strings.EqualFold(kv[0], sha256Digest) {
+			continue
+		}
+		if kv[1] != strings.TrimPrefix(digest(content), sha256Digest+digestDelimiter) {
+			return ErrDigestMismatch
+		}
+		return nil
+	}
+	return ErrDigestMissing
 }
 
 // IdProperty is a property that can readily have its id obtained
@@ -390,9 +505,71 @@ func IsPublic(s string) bool {
 	return s == PublicActivityPubIRI || s == publicJsonLD || s == publicJsonLDAS
 }
 
-// getInboxes extracts the 'inbox' IRIs from actor types.
+// getRecipients obtains the IRIs addressed by the 'to', 'bto', 'cc', 'bcc', and
+// 'audience' properties of an activity.
+func getRecipients(activity Activity) (r []*url.URL, err error) {
+	if to := activity.GetActivityStreamsTo(); to != nil {
+		for iter := to.Begin(); iter != to.End(); iter = iter.Next() {
+			var val *url.URL
+			val, err = ToId(iter)
+			if err != nil {
+				return
+			}
+			r = append(r, val)
+		}
+	}
+	if bto := activity.GetActivityStreamsBto(); bto != nil {
+		for iter := bto.Begin(); iter != bto.End(); iter = iter.Next() {
+			var val *url.URL
+			val, err = ToId(iter)
+			if err != nil {
+				return
+			}
+			r = append(r, val)
+		}
+	}
+	if cc := activity.GetActivityStreamsCc(); cc != nil {
+		for iter := cc.Begin(); iter != cc.End(); iter = iter.Next() {
+			var val *url.URL
+			val, err = ToId(iter)
+			if err != nil {
+				return
+			}
+			r = append(r, val)
+		}
+	}
+	if bcc := activity.GetActivityStreamsBcc(); bcc != nil {
+		for iter := bcc.Begin(); iter != bcc.End(); iter = iter.Next() {
+			var val *url.URL
+			val, err = ToId(iter)
+			if err != nil {
+				return
+			}
+			r = append(r, val)
+		}
+	}
+	if audience := activity.GetActivityStreamsAudience(); audience != nil {
+		for iter := audience.Begin(); iter != audience.End(); iter = iter.Next() {
+			var val *url.URL
+			val, err = ToId(iter)
+			if err != nil {
+				return
+			}
+			r = append(r, val)
+		}
+	}
+	return
+}
+
+// getInboxes extracts the 'inbox' IRIs from actor types. Actors advertising a
+// 'sharedInbox' endpoint have it used instead, so that their server receives
+// one delivery for all of them.
 func getInboxes(t []vocab.Type) (u []*url.URL, err error) {
 	for _, elem := range t {
+		if iri := getSharedInbox(elem); iri != nil {
+			u = append(u, iri)
+			continue
+		}
 		var iri *url.URL
 		iri, err = getInbox(elem)
 		if err != nil {
@@ -403,6 +580,38 @@ func getInboxes(t []vocab.Type) (u []*url.URL, err error) {
 	return
 }
 
+const (
+	// The JSON key for an actor's endpoints.
+	endpointsKey = "endpoints"
+	// The JSON key for the shared inbox within an actor's endpoints.
+	sharedInboxKey = "sharedInbox"
+)
+
+// getSharedInbox extracts the 'sharedInbox' IRI from the 'endpoints' of an
+// actor type, or returns nil if it has none.
+//
+// The ActivityStreams vocabulary does not define 'endpoints', so it is found
+// among the unknown properties.
+func getSharedInbox(t vocab.Type) *url.URL {
+	u, ok := t.(unknowner)
+	if !ok {
+		return nil
+	}
+	endpoints, ok := u.GetUnknownProperties()[endpointsKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	s, ok := endpoints[sharedInboxKey].(string)
+	if !ok {
+		return nil
+	}
+	iri, err := url.Parse(s)
+	if err != nil || len(iri.Scheme) == 0 {
+		return nil
+	}
+	return iri
+}
+
 // getInbox extracts the 'inbox' IRI from an actor type.
 func getInbox(t vocab.Type) (u *url.URL, err error) {
 	ib, ok := t.(inboxer)
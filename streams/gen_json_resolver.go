@@ -109,6 +109,8 @@ func NewJSONResolver(callbacks ...interface{}) (*JSONResolver, error) {
 			// Do nothing, this callback has a correct signature.
 		case func(context.Context, vocab.ActivityStreamsProfile) error:
 			// Do nothing, this callback has a correct signature.
+		case func(context.Context, vocab.W3IDSecurityV1PublicKey) error:
+			// Do nothing, this callback has a correct signature.
 		case func(context.Context, vocab.ActivityStreamsQuestion) error:
 			// Do nothing, this callback has a correct signature.
 		case func(context.Context, vocab.ActivityStreamsRead) error:
@@ -219,6 +221,13 @@ func (this JSONResolver) Resolve(ctx context.Context, m map[string]interface{})
 		if len(ActivityStreamsAlias) > 0 {
 			ActivityStreamsAlias += ":"
 		}
+		W3IDSecurityV1Alias, ok := aliasMap["https://w3id.org/security/v1"]
+		if !ok {
+			W3IDSecurityV1Alias, _ = aliasMap["http://w3id.org/security/v1"]
+		}
+		if len(W3IDSecurityV1Alias) > 0 {
+			W3IDSecurityV1Alias += ":"
+		}
 
 		if typeString == ActivityStreamsAlias+"Accept" {
 			v, err := mgr.DeserializeAcceptActivityStreams()(m, aliasMap)
@@ -660,6 +669,17 @@ func (this JSONResolver) Resolve(ctx context.Context, m map[string]interface{})
 				}
 			}
 			return ErrNoCallbackMatch
+		} else if typeString == W3IDSecurityV1Alias+"PublicKey" {
+			v, err := mgr.DeserializePublicKeyW3IDSecurityV1()(m, aliasMap)
+			if err != nil {
+				return err
+			}
+			for _, i := range this.callbacks {
+				if fn, ok := i.(func(context.Context, vocab.W3IDSecurityV1PublicKey) error); ok {
+					return fn(ctx, v)
+				}
+			}
+			return ErrNoCallbackMatch
 		} else if typeString == ActivityStreamsAlias+"Question" {
 			v, err := mgr.DeserializeQuestionActivityStreams()(m, aliasMap)
 			if err != nil {
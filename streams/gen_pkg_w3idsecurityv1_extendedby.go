@@ -0,0 +1,12 @@
+package streams
+
+import (
+	typepublickey "github.com/go-fed/activity/streams/impl/w3idsecurityv1/type_publickey"
+	vocab "github.com/go-fed/activity/streams/vocab"
+)
+
+// W3IDSecurityV1PublicKeyIsExtendedBy returns true if the other's type extends
+// from PublicKey.
+func W3IDSecurityV1PublicKeyIsExtendedBy(other vocab.Type) bool {
+	return typepublickey.PublicKeyIsExtendedBy(other)
+}
@@ -52,6 +52,7 @@ type ActivityStreamsAnyOfPropertyIterator struct {
 	activitystreamsPersonMember                vocab.ActivityStreamsPerson
 	activitystreamsPlaceMember                 vocab.ActivityStreamsPlace
 	activitystreamsProfileMember               vocab.ActivityStreamsProfile
+	w3idsecurityv1PublicKeyMember              vocab.W3IDSecurityV1PublicKey
 	activitystreamsQuestionMember              vocab.ActivityStreamsQuestion
 	activitystreamsReadMember                  vocab.ActivityStreamsRead
 	activitystreamsRejectMember                vocab.ActivityStreamsReject
@@ -339,6 +340,12 @@ func deserializeActivityStreamsAnyOfPropertyIterator(i interface{}, aliasMap map
 				alias:                        alias,
 			}
 			return this, nil
+		} else if v, err := mgr.DeserializePublicKeyW3IDSecurityV1()(m, aliasMap); err == nil {
+			this := &ActivityStreamsAnyOfPropertyIterator{
+				alias:                         alias,
+				w3idsecurityv1PublicKeyMember: v,
+			}
+			return this, nil
 		} else if v, err := mgr.DeserializeQuestionActivityStreams()(m, aliasMap); err == nil {
 			this := &ActivityStreamsAnyOfPropertyIterator{
 				activitystreamsQuestionMember: v,
@@ -939,6 +946,9 @@ func (this ActivityStreamsAnyOfPropertyIterator) GetType() vocab.Type {
 	if this.IsActivityStreamsProfile() {
 		return this.GetActivityStreamsProfile()
 	}
+	if this.IsW3IDSecurityV1PublicKey() {
+		return this.GetW3IDSecurityV1PublicKey()
+	}
 	if this.IsActivityStreamsQuestion() {
 		return this.GetActivityStreamsQuestion()
 	}
@@ -985,6 +995,13 @@ func (this ActivityStreamsAnyOfPropertyIterator) GetType() vocab.Type {
 	return nil
 }
 
+// GetW3IDSecurityV1PublicKey returns the value of this property. When
+// IsW3IDSecurityV1PublicKey returns false, GetW3IDSecurityV1PublicKey will
+// return an arbitrary value.
+func (this ActivityStreamsAnyOfPropertyIterator) GetW3IDSecurityV1PublicKey() vocab.W3IDSecurityV1PublicKey {
+	return this.w3idsecurityv1PublicKeyMember
+}
+
 // HasAny returns true if any of the different values is set.
 func (this ActivityStreamsAnyOfPropertyIterator) HasAny() bool {
 	return this.IsActivityStreamsObject() ||
@@ -1027,6 +1044,7 @@ func (this ActivityStreamsAnyOfPropertyIterator) HasAny() bool {
 		this.IsActivityStreamsPerson() ||
 		this.IsActivityStreamsPlace() ||
 		this.IsActivityStreamsProfile() ||
+		this.IsW3IDSecurityV1PublicKey() ||
 		this.IsActivityStreamsQuestion() ||
 		this.IsActivityStreamsRead() ||
 		this.IsActivityStreamsReject() ||
@@ -1433,6 +1451,13 @@ func (this ActivityStreamsAnyOfPropertyIterator) IsIRI() bool {
 	return this.iri != nil
 }
 
+// IsW3IDSecurityV1PublicKey returns true if this property has a type of
+// "PublicKey". When true, use the GetW3IDSecurityV1PublicKey and
+// SetW3IDSecurityV1PublicKey methods to access and set this property.
+func (this ActivityStreamsAnyOfPropertyIterator) IsW3IDSecurityV1PublicKey() bool {
+	return this.w3idsecurityv1PublicKeyMember != nil
+}
+
 // JSONLDContext returns the JSONLD URIs required in the context string for this
 // property and the specific values that are set. The value in the map is the
 // alias used to import the property's value or values.
@@ -1519,6 +1544,8 @@ func (this ActivityStreamsAnyOfPropertyIterator) JSONLDContext() map[string]stri
 		child = this.GetActivityStreamsPlace().JSONLDContext()
 	} else if this.IsActivityStreamsProfile() {
 		child = this.GetActivityStreamsProfile().JSONLDContext()
+	} else if this.IsW3IDSecurityV1PublicKey() {
+		child = this.GetW3IDSecurityV1PublicKey().JSONLDContext()
 	} else if this.IsActivityStreamsQuestion() {
 		child = this.GetActivityStreamsQuestion().JSONLDContext()
 	} else if this.IsActivityStreamsRead() {
@@ -1683,48 +1710,51 @@ func (this ActivityStreamsAnyOfPropertyIterator) KindIndex() int {
 	if this.IsActivityStreamsProfile() {
 		return 39
 	}
-	if this.IsActivityStreamsQuestion() {
+	if this.IsW3IDSecurityV1PublicKey() {
 		return 40
 	}
-	if this.IsActivityStreamsRead() {
+	if this.IsActivityStreamsQuestion() {
 		return 41
 	}
-	if this.IsActivityStreamsReject() {
+	if this.IsActivityStreamsRead() {
 		return 42
 	}
-	if this.IsActivityStreamsRelationship() {
+	if this.IsActivityStreamsReject() {
 		return 43
 	}
-	if this.IsActivityStreamsRemove() {
+	if this.IsActivityStreamsRelationship() {
 		return 44
 	}
-	if this.IsActivityStreamsService() {
+	if this.IsActivityStreamsRemove() {
 		return 45
 	}
-	if this.IsActivityStreamsTentativeAccept() {
+	if this.IsActivityStreamsService() {
 		return 46
 	}
-	if this.IsActivityStreamsTentativeReject() {
+	if this.IsActivityStreamsTentativeAccept() {
 		return 47
 	}
-	if this.IsActivityStreamsTombstone() {
+	if this.IsActivityStreamsTentativeReject() {
 		return 48
 	}
-	if this.IsActivityStreamsTravel() {
+	if this.IsActivityStreamsTombstone() {
 		return 49
 	}
-	if this.IsActivityStreamsUndo() {
+	if this.IsActivityStreamsTravel() {
 		return 50
 	}
-	if this.IsActivityStreamsUpdate() {
+	if this.IsActivityStreamsUndo() {
 		return 51
 	}
-	if this.IsActivityStreamsVideo() {
+	if this.IsActivityStreamsUpdate() {
 		return 52
 	}
-	if this.IsActivityStreamsView() {
+	if this.IsActivityStreamsVideo() {
 		return 53
 	}
+	if this.IsActivityStreamsView() {
+		return 54
+	}
 	if this.IsIRI() {
 		return -2
 	}
@@ -1822,6 +1852,8 @@ func (this ActivityStreamsAnyOfPropertyIterator) LessThan(o vocab.ActivityStream
 		return this.GetActivityStreamsPlace().LessThan(o.GetActivityStreamsPlace())
 	} else if this.IsActivityStreamsProfile() {
 		return this.GetActivityStreamsProfile().LessThan(o.GetActivityStreamsProfile())
+	} else if this.IsW3IDSecurityV1PublicKey() {
+		return this.GetW3IDSecurityV1PublicKey().LessThan(o.GetW3IDSecurityV1PublicKey())
 	} else if this.IsActivityStreamsQuestion() {
 		return this.GetActivityStreamsQuestion().LessThan(o.GetActivityStreamsQuestion())
 	} else if this.IsActivityStreamsRead() {
@@ -2263,6 +2295,13 @@ func (this *ActivityStreamsAnyOfPropertyIterator) SetIRI(v *url.URL) {
 	this.iri = v
 }
 
+// SetW3IDSecurityV1PublicKey sets the value of this property. Calling
+// IsW3IDSecurityV1PublicKey afterwards returns true.
+func (this *ActivityStreamsAnyOfPropertyIterator) SetW3IDSecurityV1PublicKey(v vocab.W3IDSecurityV1PublicKey) {
+	this.clear()
+	this.w3idsecurityv1PublicKeyMember = v
+}
+
 // clear ensures no value of this property is set. Calling HasAny or any of the
 // 'Is' methods afterwards will return false.
 func (this *ActivityStreamsAnyOfPropertyIterator) clear() {
@@ -2306,6 +2345,7 @@ func (this *ActivityStreamsAnyOfPropertyIterator) clear() {
 	this.activitystreamsPersonMember = nil
 	this.activitystreamsPlaceMember = nil
 	this.activitystreamsProfileMember = nil
+	this.w3idsecurityv1PublicKeyMember = nil
 	this.activitystreamsQuestionMember = nil
 	this.activitystreamsReadMember = nil
 	this.activitystreamsRejectMember = nil
@@ -2409,6 +2449,8 @@ func (this ActivityStreamsAnyOfPropertyIterator) serialize() (interface{}, error
 		return this.GetActivityStreamsPlace().Serialize()
 	} else if this.IsActivityStreamsProfile() {
 		return this.GetActivityStreamsProfile().Serialize()
+	} else if this.IsW3IDSecurityV1PublicKey() {
+		return this.GetW3IDSecurityV1PublicKey().Serialize()
 	} else if this.IsActivityStreamsQuestion() {
 		return this.GetActivityStreamsQuestion().Serialize()
 	} else if this.IsActivityStreamsRead() {
@@ -3113,6 +3155,18 @@ func (this *ActivityStreamsAnyOfProperty) AppendIRI(v *url.URL) {
 	})
 }
 
+// AppendW3IDSecurityV1PublicKey appends a PublicKey value to the back of a list
+// of the property "anyOf". Invalidates iterators that are traversing using
+// Prev.
+func (this *ActivityStreamsAnyOfProperty) AppendW3IDSecurityV1PublicKey(v vocab.W3IDSecurityV1PublicKey) {
+	this.properties = append(this.properties, &ActivityStreamsAnyOfPropertyIterator{
+		alias:                         this.alias,
+		myIdx:                         this.Len(),
+		parent:                        this,
+		w3idsecurityv1PublicKeyMember: v,
+	})
+}
+
 // At returns the property value for the specified index. Panics if the index is
 // out of bounds.
 func (this ActivityStreamsAnyOfProperty) At(index int) vocab.ActivityStreamsAnyOfPropertyIterator {
@@ -3343,58 +3397,62 @@ func (this ActivityStreamsAnyOfProperty) Less(i, j int) bool {
 			rhs := this.properties[j].GetActivityStreamsProfile()
 			return lhs.LessThan(rhs)
 		} else if idx1 == 40 {
+			lhs := this.properties[i].GetW3IDSecurityV1PublicKey()
+			rhs := this.properties[j].GetW3IDSecurityV1PublicKey()
+			return lhs.LessThan(rhs)
+		} else if idx1 == 41 {
 			lhs := this.properties[i].GetActivityStreamsQuestion()
 			rhs := this.properties[j].GetActivityStreamsQuestion()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 41 {
+		} else if idx1 == 42 {
 			lhs := this.properties[i].GetActivityStreamsRead()
 			rhs := this.properties[j].GetActivityStreamsRead()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 42 {
+		} else if idx1 == 43 {
 			lhs := this.properties[i].GetActivityStreamsReject()
 			rhs := this.properties[j].GetActivityStreamsReject()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 43 {
+		} else if idx1 == 44 {
 			lhs := this.properties[i].GetActivityStreamsRelationship()
 			rhs := this.properties[j].GetActivityStreamsRelationship()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 44 {
+		} else if idx1 == 45 {
 			lhs := this.properties[i].GetActivityStreamsRemove()
 			rhs := this.properties[j].GetActivityStreamsRemove()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 45 {
+		} else if idx1 == 46 {
 			lhs := this.properties[i].GetActivityStreamsService()
 			rhs := this.properties[j].GetActivityStreamsService()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 46 {
+		} else if idx1 == 47 {
 			lhs := this.properties[i].GetActivityStreamsTentativeAccept()
 			rhs := this.properties[j].GetActivityStreamsTentativeAccept()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 47 {
+		} else if idx1 == 48 {
 			lhs := this.properties[i].GetActivityStreamsTentativeReject()
 			rhs := this.properties[j].GetActivityStreamsTentativeReject()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 48 {
+		} else if idx1 == 49 {
 			lhs := this.properties[i].GetActivityStreamsTombstone()
 			rhs := this.properties[j].GetActivityStreamsTombstone()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 49 {
+		} else if idx1 == 50 {
 			lhs := this.properties[i].GetActivityStreamsTravel()
 			rhs := this.properties[j].GetActivityStreamsTravel()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 50 {
+		} else if idx1 == 51 {
 			lhs := this.properties[i].GetActivityStreamsUndo()
 			rhs := this.properties[j].GetActivityStreamsUndo()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 51 {
+		} else if idx1 == 52 {
 			lhs := this.properties[i].GetActivityStreamsUpdate()
 			rhs := this.properties[j].GetActivityStreamsUpdate()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 52 {
+		} else if idx1 == 53 {
 			lhs := this.properties[i].GetActivityStreamsVideo()
 			rhs := this.properties[j].GetActivityStreamsVideo()
 			return lhs.LessThan(rhs)
-		} else if idx1 == 53 {
+		} else if idx1 == 54 {
 			lhs := this.properties[i].GetActivityStreamsView()
 			rhs := this.properties[j].GetActivityStreamsView()
 			return lhs.LessThan(rhs)
@@ -4204,6 +4262,20 @@ func (this *ActivityStreamsAnyOfProperty) PrependIRI(v *url.URL) {
 	}
 }
 
+// PrependW3IDSecurityV1PublicKey prepends a PublicKey value to the front of a
+// list of the property "anyOf". Invalidates all iterators.
+func (this *ActivityStreamsAnyOfProperty) PrependW3IDSecurityV1PublicKey(v vocab.W3IDSecurityV1PublicKey) {
+	this.properties = append([]*ActivityStreamsAnyOfPropertyIterator{{
+		alias:                         this.alias,
+		myIdx:                         0,
+		parent:                        this,
+		w3idsecurityv1PublicKeyMember: v,
+	}}, this.properties...)
+	for i := 1; i < this.Len(); i++ {
+		(this.properties)[i].myIdx = i
+	}
+}
+
 // Remove deletes an element at the specified index from a list of the property
 // "anyOf", regardless of its type. Panics if the index is out of bounds.
 // Invalidates all iterators.
@@ -4951,6 +5023,19 @@ func (this *ActivityStreamsAnyOfProperty) SetIRI(idx int, v *url.URL) {
 	}
 }
 
+// SetW3IDSecurityV1PublicKey sets a PublicKey value to be at the specified index
+// for the property "anyOf". Panics if the index is out of bounds. Invalidates
+// all iterators.
+func (this *ActivityStreamsAnyOfProperty) SetW3IDSecurityV1PublicKey(idx int, v vocab.W3IDSecurityV1PublicKey) {
+	(this.properties)[idx].parent = nil
+	(this.properties)[idx] = &ActivityStreamsAnyOfPropertyIterator{
+		alias:                         this.alias,
+		myIdx:                         idx,
+		parent:                        this,
+		w3idsecurityv1PublicKeyMember: v,
+	}
+}
+
 // Swap swaps the location of values at two indices for the "anyOf" property.
 func (this ActivityStreamsAnyOfProperty) Swap(i, j int) {
 	this.properties[i], this.properties[j] = this.properties[j], this.properties[i]
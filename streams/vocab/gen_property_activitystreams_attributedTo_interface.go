@@ -230,6 +230,10 @@ type ActivityStreamsAttributedToPropertyIterator interface {
 	// the value is not an ActivityStreams type, such as an IRI or another
 	// value.
 	GetType() Type
+	// GetW3IDSecurityV1PublicKey returns the value of this property. When
+	// IsW3IDSecurityV1PublicKey returns false, GetW3IDSecurityV1PublicKey
+	// will return an arbitrary value.
+	GetW3IDSecurityV1PublicKey() W3IDSecurityV1PublicKey
 	// HasAny returns true if any of the different values is set.
 	HasAny() bool
 	// IsActivityStreamsAccept returns true if this property has a type of
@@ -467,6 +471,10 @@ type ActivityStreamsAttributedToPropertyIterator interface {
 	// IsIRI returns true if this property is an IRI. When true, use GetIRI
 	// and SetIRI to access and set this property
 	IsIRI() bool
+	// IsW3IDSecurityV1PublicKey returns true if this property has a type of
+	// "PublicKey". When true, use the GetW3IDSecurityV1PublicKey and
+	// SetW3IDSecurityV1PublicKey methods to access and set this property.
+	IsW3IDSecurityV1PublicKey() bool
 	// JSONLDContext returns the JSONLD URIs required in the context string
 	// for this property and the specific values that are set. The value
 	// in the map is the alias used to import the property's value or
@@ -655,6 +663,9 @@ type ActivityStreamsAttributedToPropertyIterator interface {
 	// SetIRI sets the value of this property. Calling IsIRI afterwards
 	// returns true.
 	SetIRI(v *url.URL)
+	// SetW3IDSecurityV1PublicKey sets the value of this property. Calling
+	// IsW3IDSecurityV1PublicKey afterwards returns true.
+	SetW3IDSecurityV1PublicKey(v W3IDSecurityV1PublicKey)
 }
 
 // Identifies one or more entities to which this object is attributed. The
@@ -907,6 +918,10 @@ type ActivityStreamsAttributedToProperty interface {
 	// AppendIRI appends an IRI value to the back of a list of the property
 	// "attributedTo"
 	AppendIRI(v *url.URL)
+	// AppendW3IDSecurityV1PublicKey appends a PublicKey value to the back of
+	// a list of the property "attributedTo". Invalidates iterators that
+	// are traversing using Prev.
+	AppendW3IDSecurityV1PublicKey(v W3IDSecurityV1PublicKey)
 	// At returns the property value for the specified index. Panics if the
 	// index is out of bounds.
 	At(index int) ActivityStreamsAttributedToPropertyIterator
@@ -1118,6 +1133,9 @@ type ActivityStreamsAttributedToProperty interface {
 	// PrependIRI prepends an IRI value to the front of a list of the property
 	// "attributedTo".
 	PrependIRI(v *url.URL)
+	// PrependW3IDSecurityV1PublicKey prepends a PublicKey value to the front
+	// of a list of the property "attributedTo". Invalidates all iterators.
+	PrependW3IDSecurityV1PublicKey(v W3IDSecurityV1PublicKey)
 	// Remove deletes an element at the specified index from a list of the
 	// property "attributedTo", regardless of its type. Panics if the
 	// index is out of bounds. Invalidates all iterators.
@@ -1347,6 +1365,10 @@ type ActivityStreamsAttributedToProperty interface {
 	// SetIRI sets an IRI value to be at the specified index for the property
 	// "attributedTo". Panics if the index is out of bounds.
 	SetIRI(idx int, v *url.URL)
+	// SetW3IDSecurityV1PublicKey sets a PublicKey value to be at the
+	// specified index for the property "attributedTo". Panics if the
+	// index is out of bounds. Invalidates all iterators.
+	SetW3IDSecurityV1PublicKey(idx int, v W3IDSecurityV1PublicKey)
 	// Swap swaps the location of values at two indices for the "attributedTo"
 	// property.
 	Swap(i, j int)
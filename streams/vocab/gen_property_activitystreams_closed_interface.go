@@ -233,6 +233,10 @@ type ActivityStreamsClosedPropertyIterator interface {
 	// the value is not an ActivityStreams type, such as an IRI or another
 	// value.
 	GetType() Type
+	// GetW3IDSecurityV1PublicKey returns the value of this property. When
+	// IsW3IDSecurityV1PublicKey returns false, GetW3IDSecurityV1PublicKey
+	// will return an arbitrary value.
+	GetW3IDSecurityV1PublicKey() W3IDSecurityV1PublicKey
 	// GetXMLSchemaBoolean returns the value of this property. When
 	// IsXMLSchemaBoolean returns false, GetXMLSchemaBoolean will return
 	// an arbitrary value.
@@ -478,6 +482,10 @@ type ActivityStreamsClosedPropertyIterator interface {
 	// IsIRI returns true if this property is an IRI. When true, use GetIRI
 	// and SetIRI to access and set this property
 	IsIRI() bool
+	// IsW3IDSecurityV1PublicKey returns true if this property has a type of
+	// "PublicKey". When true, use the GetW3IDSecurityV1PublicKey and
+	// SetW3IDSecurityV1PublicKey methods to access and set this property.
+	IsW3IDSecurityV1PublicKey() bool
 	// IsXMLSchemaBoolean returns true if this property has a type of
 	// "boolean". When true, use the GetXMLSchemaBoolean and
 	// SetXMLSchemaBoolean methods to access and set this property.
@@ -674,6 +682,9 @@ type ActivityStreamsClosedPropertyIterator interface {
 	// SetIRI sets the value of this property. Calling IsIRI afterwards
 	// returns true.
 	SetIRI(v *url.URL)
+	// SetW3IDSecurityV1PublicKey sets the value of this property. Calling
+	// IsW3IDSecurityV1PublicKey afterwards returns true.
+	SetW3IDSecurityV1PublicKey(v W3IDSecurityV1PublicKey)
 	// SetXMLSchemaBoolean sets the value of this property. Calling
 	// IsXMLSchemaBoolean afterwards returns true.
 	SetXMLSchemaBoolean(v bool)
@@ -910,6 +921,10 @@ type ActivityStreamsClosedProperty interface {
 	// AppendIRI appends an IRI value to the back of a list of the property
 	// "closed"
 	AppendIRI(v *url.URL)
+	// AppendW3IDSecurityV1PublicKey appends a PublicKey value to the back of
+	// a list of the property "closed". Invalidates iterators that are
+	// traversing using Prev.
+	AppendW3IDSecurityV1PublicKey(v W3IDSecurityV1PublicKey)
 	// AppendXMLSchemaBoolean appends a boolean value to the back of a list of
 	// the property "closed". Invalidates iterators that are traversing
 	// using Prev.
@@ -1124,6 +1139,9 @@ type ActivityStreamsClosedProperty interface {
 	// PrependIRI prepends an IRI value to the front of a list of the property
 	// "closed".
 	PrependIRI(v *url.URL)
+	// PrependW3IDSecurityV1PublicKey prepends a PublicKey value to the front
+	// of a list of the property "closed". Invalidates all iterators.
+	PrependW3IDSecurityV1PublicKey(v W3IDSecurityV1PublicKey)
 	// PrependXMLSchemaBoolean prepends a boolean value to the front of a list
 	// of the property "closed". Invalidates all iterators.
 	PrependXMLSchemaBoolean(v bool)
@@ -1359,6 +1377,10 @@ type ActivityStreamsClosedProperty interface {
 	// SetIRI sets an IRI value to be at the specified index for the property
 	// "closed". Panics if the index is out of bounds.
 	SetIRI(idx int, v *url.URL)
+	// SetW3IDSecurityV1PublicKey sets a PublicKey value to be at the
+	// specified index for the property "closed". Panics if the index is
+	// out of bounds. Invalidates all iterators.
+	SetW3IDSecurityV1PublicKey(idx int, v W3IDSecurityV1PublicKey)
 	// SetXMLSchemaBoolean sets a boolean value to be at the specified index
 	// for the property "closed". Panics if the index is out of bounds.
 	// Invalidates all iterators.
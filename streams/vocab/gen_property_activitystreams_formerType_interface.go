@@ -222,6 +222,10 @@ type ActivityStreamsFormerTypePropertyIterator interface {
 	// the value is not an ActivityStreams type, such as an IRI or another
 	// value.
 	GetType() Type
+	// GetW3IDSecurityV1PublicKey returns the value of this property. When
+	// IsW3IDSecurityV1PublicKey returns false, GetW3IDSecurityV1PublicKey
+	// will return an arbitrary value.
+	GetW3IDSecurityV1PublicKey() W3IDSecurityV1PublicKey
 	// GetXMLSchemaString returns the value of this property. When
 	// IsXMLSchemaString returns false, GetXMLSchemaString will return an
 	// arbitrary value.
@@ -455,6 +459,10 @@ type ActivityStreamsFormerTypePropertyIterator interface {
 	// IsIRI returns true if this property is an IRI. When true, use GetIRI
 	// and SetIRI to access and set this property
 	IsIRI() bool
+	// IsW3IDSecurityV1PublicKey returns true if this property has a type of
+	// "PublicKey". When true, use the GetW3IDSecurityV1PublicKey and
+	// SetW3IDSecurityV1PublicKey methods to access and set this property.
+	IsW3IDSecurityV1PublicKey() bool
 	// IsXMLSchemaString returns true if this property has a type of "string".
 	// When true, use the GetXMLSchemaString and SetXMLSchemaString
 	// methods to access and set this property.
@@ -641,6 +649,9 @@ type ActivityStreamsFormerTypePropertyIterator interface {
 	// SetIRI sets the value of this property. Calling IsIRI afterwards
 	// returns true.
 	SetIRI(v *url.URL)
+	// SetW3IDSecurityV1PublicKey sets the value of this property. Calling
+	// IsW3IDSecurityV1PublicKey afterwards returns true.
+	SetW3IDSecurityV1PublicKey(v W3IDSecurityV1PublicKey)
 	// SetXMLSchemaString sets the value of this property. Calling
 	// IsXMLSchemaString afterwards returns true.
 	SetXMLSchemaString(v string)
@@ -868,6 +879,10 @@ type ActivityStreamsFormerTypeProperty interface {
 	// AppendIRI appends an IRI value to the back of a list of the property
 	// "formerType"
 	AppendIRI(v *url.URL)
+	// AppendW3IDSecurityV1PublicKey appends a PublicKey value to the back of
+	// a list of the property "formerType". Invalidates iterators that are
+	// traversing using Prev.
+	AppendW3IDSecurityV1PublicKey(v W3IDSecurityV1PublicKey)
 	// AppendXMLSchemaString appends a string value to the back of a list of
 	// the property "formerType". Invalidates iterators that are
 	// traversing using Prev.
@@ -1077,6 +1092,9 @@ type ActivityStreamsFormerTypeProperty interface {
 	// PrependIRI prepends an IRI value to the front of a list of the property
 	// "formerType".
 	PrependIRI(v *url.URL)
+	// PrependW3IDSecurityV1PublicKey prepends a PublicKey value to the front
+	// of a list of the property "formerType". Invalidates all iterators.
+	PrependW3IDSecurityV1PublicKey(v W3IDSecurityV1PublicKey)
 	// PrependXMLSchemaString prepends a string value to the front of a list
 	// of the property "formerType". Invalidates all iterators.
 	PrependXMLSchemaString(v string)
@@ -1301,6 +1319,10 @@ type ActivityStreamsFormerTypeProperty interface {
 	// SetIRI sets an IRI value to be at the specified index for the property
 	// "formerType". Panics if the index is out of bounds.
 	SetIRI(idx int, v *url.URL)
+	// SetW3IDSecurityV1PublicKey sets a PublicKey value to be at the
+	// specified index for the property "formerType". Panics if the index
+	// is out of bounds. Invalidates all iterators.
+	SetW3IDSecurityV1PublicKey(idx int, v W3IDSecurityV1PublicKey)
 	// SetXMLSchemaString sets a string value to be at the specified index for
 	// the property "formerType". Panics if the index is out of bounds.
 	// Invalidates all iterators.